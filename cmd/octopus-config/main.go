@@ -0,0 +1,314 @@
+// Command octopus-config validates, dumps, diffs and converts config files.
+//
+//	octopus-config validate [-f format] file...
+//	octopus-config dump [-f format] [-o format] file...
+//	octopus-config diff [-f format] old new
+//	octopus-config convert [-f format] -o format in [out]
+//	octopus-config gen [-f format] [-p package] [-n name] file...
+//
+// The format of file is detected by its extension or content if -f is not
+// set. The files of dump and gen are in priority order, the former ones take
+// precedence like config.MultiConfig. validate and diff exit with 1 if
+// any file is invalid or the configs differ.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource/localfile"
+	"github.com/k8s-practice/octopus/config/gen"
+	"github.com/k8s-practice/octopus/config/parser"
+	_ "github.com/k8s-practice/octopus/config/parser/dotenvparser"
+	_ "github.com/k8s-practice/octopus/config/parser/hclparser"
+	_ "github.com/k8s-practice/octopus/config/parser/iniparser"
+	_ "github.com/k8s-practice/octopus/config/parser/json5parser"
+	_ "github.com/k8s-practice/octopus/config/parser/jsonparser"
+	_ "github.com/k8s-practice/octopus/config/parser/propertiesparser"
+	_ "github.com/k8s-practice/octopus/config/parser/tomlparser"
+	_ "github.com/k8s-practice/octopus/config/parser/yamlparser"
+	"github.com/k8s-practice/octopus/xlog"
+)
+
+const usage = `Usage:
+  octopus-config validate [-f format] file...
+  octopus-config dump [-f format] [-o format] file...
+  octopus-config diff [-f format] old new
+  octopus-config convert [-f format] -o format in [out]
+  octopus-config gen [-f format] [-p package] [-n name] file...
+`
+
+// commands maps the name to the subcommand.
+var commands = map[string]func(c *command) error{
+	"validate": validate,
+	"dump":     dump,
+	"diff":     diff,
+	"convert":  convert,
+	"gen":      generate,
+}
+
+func main() {
+	xlog.SetLevel(xlog.ErrorLevel)
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// errFailed reports the failure which is already printed.
+type errFailed struct{}
+
+func (errFailed) Error() string { return "failed" }
+
+// command is the parsed subcommand.
+type command struct {
+	flags  *flag.FlagSet
+	format string
+	output string
+	pkg    string
+	name   string
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// run runs the subcommand in args, returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || commands[args[0]] == nil {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	c := &command{flags: flag.NewFlagSet(args[0], flag.ContinueOnError), stdout: stdout, stderr: stderr}
+	c.flags.SetOutput(stderr)
+	c.flags.StringVar(&c.format, "f", "", "Format of the input files, detected if empty.")
+	switch args[0] {
+	case "dump", "convert":
+		c.flags.StringVar(&c.output, "o", "", "Format of the output, "+strings.Join(encoders(), ", ")+".")
+	case "gen":
+		c.flags.StringVar(&c.pkg, "p", "config", "Package of the generated code.")
+		c.flags.StringVar(&c.name, "n", "Config", "Name of the root struct.")
+	}
+	if err := c.flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	switch err := commands[args[0]](c); err.(type) {
+	case nil:
+		return 0
+	case errFailed:
+		return 1
+	default:
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+}
+
+// load loads the files into a config in priority order.
+func (c *command) load(files ...string) (config.Config, error) {
+	layers := make([]config.Config, 0, len(files))
+	for _, file := range files {
+		layer, err := config.New(config.T().WithScheme(localfile.Scheme()).WithPath(file).WithFormat(c.format))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+
+	return config.MultiConfig(layers...), nil
+}
+
+func validate(c *command) error {
+	if c.flags.NArg() == 0 {
+		return fmt.Errorf("No file to validate.")
+	}
+
+	var failed bool
+	for _, file := range c.flags.Args() {
+		if _, err := c.load(file); err != nil {
+			failed = true
+			fmt.Fprintf(c.stderr, "FAIL %s\n%v\n", file, err)
+		} else {
+			fmt.Fprintf(c.stdout, "ok   %s\n", file)
+		}
+	}
+	if failed {
+		return errFailed{}
+	}
+
+	return nil
+}
+
+func dump(c *command) error {
+	if c.flags.NArg() == 0 {
+		return fmt.Errorf("No file to dump.")
+	}
+	if c.output == "" {
+		c.output = "yaml"
+	}
+
+	cfg, err := c.load(c.flags.Args()...)
+	if err != nil {
+		return err
+	}
+
+	return c.write(c.stdout, config.Tree(cfg))
+}
+
+func convert(c *command) error {
+	if n := c.flags.NArg(); n != 1 && n != 2 {
+		return fmt.Errorf("Expect input and optional output file.")
+	}
+	if c.output == "" {
+		return fmt.Errorf("Output format is required.")
+	}
+
+	cfg, err := c.load(c.flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if c.flags.NArg() == 1 {
+		return c.write(c.stdout, config.Tree(cfg))
+	}
+
+	f, err := os.Create(c.flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	if err = c.write(f, config.Tree(cfg)); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func generate(c *command) error {
+	if c.flags.NArg() == 0 {
+		return fmt.Errorf("No file to generate from.")
+	}
+
+	cfg, err := c.load(c.flags.Args()...)
+	if err != nil {
+		return err
+	}
+
+	code, err := gen.Generate(config.Tree(cfg), c.pkg, c.name)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.stdout.Write(code)
+	return err
+}
+
+func (c *command) write(w io.Writer, tree map[string]interface{}) error {
+	data, err := parser.Marshal(c.output, tree)
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// encoders returns the formats supporting Marshal.
+func encoders() []string {
+	var formats []string
+	for _, format := range parser.Formats() {
+		if _, err := parser.Marshal(format, map[string]interface{}{}); err == nil {
+			formats = append(formats, format)
+		}
+	}
+
+	return formats
+}
+
+func diff(c *command) error {
+	if c.flags.NArg() != 2 {
+		return fmt.Errorf("Expect two files to diff.")
+	}
+
+	old, err := c.load(c.flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	cur, err := c.load(c.flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	before, after := flatten(config.Tree(old)), flatten(config.Tree(cur))
+	keys := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var changed bool
+	for _, k := range keys {
+		b, inBefore := before[k]
+		a, inAfter := after[k]
+		switch {
+		case !inAfter:
+			fmt.Fprintf(c.stdout, "- %s: %s\n", k, show(b))
+		case !inBefore:
+			fmt.Fprintf(c.stdout, "+ %s: %s\n", k, show(a))
+		case show(a) != show(b):
+			// Compares the shown values, so that 1 in yaml equals to 1.0 in json.
+			fmt.Fprintf(c.stdout, "~ %s: %s -> %s\n", k, show(b), show(a))
+		default:
+			continue
+		}
+		changed = true
+	}
+	if changed {
+		return errFailed{}
+	}
+
+	return nil
+}
+
+// flatten flattens the nested maps in m into keys joined by
+// config.DEFAULT_KEY_DELIMITER, the other values are leaves.
+func flatten(m map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			key := k
+			if prefix != "" {
+				key = prefix + config.DEFAULT_KEY_DELIMITER + k
+			}
+			if sub, ok := v.(map[string]interface{}); ok && len(sub) > 0 {
+				walk(key, sub)
+			} else {
+				flat[key] = v
+			}
+		}
+	}
+	walk("", m)
+
+	return flat
+}
+
+// show formats v as JSON, or by fmt if it's not JSON encodable.
+func show(v interface{}) string {
+	if data, err := json.Marshal(v); err == nil {
+		return string(data)
+	}
+
+	return fmt.Sprint(v)
+}
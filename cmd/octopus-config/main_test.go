@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := os.MkdirTemp("", "octopus-config")
+	assert.Nil(t, err)
+	for name, data := range files {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0644))
+	}
+
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.yaml":   "database:\n  addr: a\n  port: 3306\n",
+		"local.json": `{"database": {"addr": "b", "user": "root"}}`,
+		"bad.toml":   "a = \n",
+	})
+	defer os.RemoveAll(dir)
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	var stdout, stderr bytes.Buffer
+	reset := func() { stdout.Reset(); stderr.Reset() }
+
+	assert.Equal(t, 0, run([]string{"validate", path("app.yaml"), path("local.json")}, &stdout, &stderr))
+	reset()
+	assert.Equal(t, 1, run([]string{"validate", path("app.yaml"), path("bad.toml")}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "line 1")
+
+	reset()
+	assert.Equal(t, 0, run([]string{"dump", "-o", "json", path("local.json"), path("app.yaml")}, &stdout, &stderr))
+	assert.JSONEq(t, `{"database": {"addr": "b", "port": 3306, "user": "root"}}`, stdout.String())
+
+	reset()
+	assert.Equal(t, 1, run([]string{"diff", path("app.yaml"), path("local.json")}, &stdout, &stderr))
+	assert.Equal(t, "~ database.addr: \"a\" -> \"b\"\n- database.port: 3306\n+ database.user: \"root\"\n", stdout.String())
+
+	reset()
+	assert.Equal(t, 0, run([]string{"convert", "-o", "toml", path("app.yaml"), path("app.toml")}, &stdout, &stderr))
+	assert.Equal(t, 0, run([]string{"diff", path("app.yaml"), path("app.toml")}, &stdout, &stderr))
+
+	reset()
+	assert.Equal(t, 0, run([]string{"gen", "-p", "conf", path("app.yaml")}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "package conf")
+	assert.Contains(t, stdout.String(), "Database ConfigDatabase `mapstructure:\"database\"`")
+
+	reset()
+	assert.Equal(t, 2, run([]string{"unknown"}, &stdout, &stderr))
+}
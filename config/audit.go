@@ -0,0 +1,195 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/k8s-practice/octopus/config/datasource"
+	"github.com/k8s-practice/octopus/internal/configsearch"
+)
+
+// Auditor wraps a Config and records every key read through Get, it reports
+// the keys present in the datasources but never read, and the keys read but
+// missing. Octopus.Load records the fields of the struct it decodes.
+type Auditor struct {
+	c     Config
+	delim string
+
+	mu sync.Mutex
+	// read maps the read key to whether the value is present.
+	read map[string]bool
+}
+
+// AuditReport is the result of auditing.
+type AuditReport struct {
+	// Unused are the keys present in the datasources but never read.
+	Unused []string
+
+	// Missing are the keys read but not present.
+	Missing []string
+}
+
+// Audit creates an Auditor recording the reads of c, the Auditor should be
+// used instead of c.
+func Audit(c Config) *Auditor {
+	a := &Auditor{c: c, delim: DEFAULT_KEY_DELIMITER, read: make(map[string]bool)}
+	if first := firstConfig(c); first != nil {
+		a.delim = first.delim
+	}
+
+	return a
+}
+
+// Get gets value by key from the audited Config, and records key.
+func (a *Auditor) Get(key string) interface{} {
+	v := a.c.Get(key)
+	a.Record(key, v != nil)
+
+	return v
+}
+
+// ReadRecorder is implemented by the Config recording the keys read, e.g.
+// Auditor. The Configs wrapping a ReadRecorder, e.g. MultiConfig, forward
+// the records to it.
+type ReadRecorder interface {
+	Config
+
+	// Record records key is read, found reports whether the value is
+	// present.
+	Record(key string, found bool)
+
+	// Peek gets value by key without recording.
+	Peek(key string) interface{}
+}
+
+// peek gets value by key from c without recording.
+func peek(c Config, key string) interface{} {
+	if r, ok := c.(ReadRecorder); ok {
+		return r.Peek(key)
+	}
+
+	return c.Get(key)
+}
+
+// Record records key is read, found reports whether the value is present.
+// A read key covers all keys nested in it.
+func (a *Auditor) Record(key string, found bool) {
+	a.mu.Lock()
+	a.read[key] = a.read[key] || found
+	a.mu.Unlock()
+
+	if r, ok := a.c.(ReadRecorder); ok {
+		r.Record(key, found)
+	}
+}
+
+// Peek gets value by key from the audited Config without recording.
+func (a *Auditor) Peek(key string) interface{} {
+	return peek(a.c, key)
+}
+
+// Unwrap returns the audited Config.
+func (a *Auditor) Unwrap() Config {
+	return a.c
+}
+
+// Reset forgets all recorded keys.
+func (a *Auditor) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.read = make(map[string]bool)
+}
+
+// Report reports the unused keys of the current data, and the missing keys,
+// both are sorted. The slices are leaves, a key nested in a slice covers it.
+// Keys are compared case-insensitively.
+func (a *Auditor) Report() AuditReport {
+	a.mu.Lock()
+	read := make([][]string, 0, len(a.read))
+	var report AuditReport
+	for key, found := range a.read {
+		read = append(read, configsearch.SplitKey(strings.ToLower(key), a.delim))
+		if !found {
+			report.Missing = append(report.Missing, key)
+		}
+	}
+	a.mu.Unlock()
+
+	var walk func(path []string, v interface{})
+	walk = func(path []string, v interface{}) {
+		if m, ok := v.(map[string]interface{}); ok && (len(m) > 0 || len(path) == 0) {
+			for k, x := range m {
+				walk(append(path[:len(path):len(path)], k), x)
+			}
+			return
+		}
+
+		for _, r := range read {
+			if covers(r, path) {
+				return
+			}
+		}
+		report.Unused = append(report.Unused, strings.Join(path, a.delim))
+	}
+	walk(nil, tree(a.c))
+
+	sort.Strings(report.Unused)
+	sort.Strings(report.Missing)
+
+	return report
+}
+
+// covers reports whether the read key covers the leaf path, i.e. either one
+// is a prefix of the other. Wildcard in read matches any segment.
+func covers(read, path []string) bool {
+	for i := 0; i < len(read) && i < len(path); i++ {
+		if read[i] != configsearch.Wildcard && read[i] != strings.ToLower(path[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Load reloads the audited Config.
+func (a *Auditor) Load() error {
+	return Reload(a.c)
+}
+
+// Watch registers f to the audited Config if it supports watching.
+func (a *Auditor) Watch(f func()) {
+	if w, ok := a.c.(Watcher); ok {
+		w.Watch(f)
+	}
+}
+
+// Set sets value by key on the audited Config.
+func (a *Auditor) Set(key string, value interface{}) error {
+	return Set(a.c, key, value)
+}
+
+// Delete deletes value by key from the audited Config.
+func (a *Auditor) Delete(key string) error {
+	return Delete(a.c, key)
+}
+
+// Persist persists the audited Config.
+func (a *Auditor) Persist() error {
+	return Persist(a.c)
+}
+
+// Status returns the status of the audited Config.
+func (a *Auditor) Status() []datasource.Status {
+	return Status(a.c)
+}
+
+// Versions returns the versions of the audited Config.
+func (a *Auditor) Versions() []datasource.Version {
+	return Versions(a.c)
+}
+
+// Rollback rolls back the audited Config.
+func (a *Auditor) Rollback(id int) error {
+	return Rollback(a.c, id)
+}
@@ -1,9 +1,10 @@
 package config
 
 import (
+	"errors"
 	"time"
 
-	"github.com/k8s-practice/octopus/utils/cast"
+	"github.com/k8s-practice/octopus/config/datasource"
 	// To register jsonparser
 	//_ "github.com/k8s-practice/octopus/config/parser/jsonparser"
 	// To register tomlparser
@@ -20,62 +21,190 @@ type Config interface {
 	Get(key string) interface{}
 }
 
+// Loader is implemented by the Config which is able to reload its datasources.
+type Loader interface {
+	// Load reloads data from datasources.
+	Load() error
+}
+
+// Watcher is implemented by the Config whose values change after reloading.
+type Watcher interface {
+	// Watch registers f, f will be invoked after every successful reload.
+	Watch(f func())
+}
+
+// Writer is implemented by the Config which is able to modify its data.
+type Writer interface {
+	// Set sets value by key, it takes effect immediately.
+	Set(key string, value interface{}) error
+
+	// Delete deletes value by key, it takes effect immediately.
+	Delete(key string) error
+
+	// Persist writes the modified data back to datasources.
+	Persist() error
+}
+
+// StatusReporter is implemented by the Config which reports the status of
+// its datasources, e.g. for health checks.
+type StatusReporter interface {
+	// Status returns the status of all datasources in priority order.
+	Status() []datasource.Status
+}
+
+// Versioned is implemented by the Config which keeps the versions of its
+// datasource.
+type Versioned interface {
+	// Versions returns the kept versions, the latest one is the current.
+	Versions() []datasource.Version
+
+	// Rollback makes the data of version id take effect again.
+	Rollback(id int) error
+}
+
+// ErrReadOnly is returned while modifying a Config without writable
+// datasources.
+var ErrReadOnly = errors.New("Config is read-only.")
+
+// ErrNoHistory is returned while rolling back a Config which doesn't keep
+// versions.
+var ErrNoHistory = errors.New("Config has no history.")
+
+// Status returns the status of the datasources of c, or nil if c doesn't
+// implement StatusReporter.
+func Status(c Config) []datasource.Status {
+	if r, ok := c.(StatusReporter); ok {
+		return r.Status()
+	}
+
+	return nil
+}
+
+// LastError returns the first error of the last Loads of the datasources of
+// c, nil means all datasources are healthy.
+func LastError(c Config) error {
+	for _, s := range Status(c) {
+		if s.LastError != nil {
+			return s.LastError
+		}
+	}
+
+	return nil
+}
+
+// Versions returns the kept versions of c, or nil if c doesn't implement
+// Versioned.
+func Versions(c Config) []datasource.Version {
+	if v, ok := c.(Versioned); ok {
+		return v.Versions()
+	}
+
+	return nil
+}
+
+// Rollback makes the data of version id of c take effect again without
+// touching the storage, e.g. to revert a bad reload.
+func Rollback(c Config, id int) error {
+	if v, ok := c.(Versioned); ok {
+		return v.Rollback(id)
+	}
+
+	return ErrNoHistory
+}
+
+// Reload reloads c if it implements Loader, otherwise does nothing.
+func Reload(c Config) error {
+	if l, ok := c.(Loader); ok {
+		return l.Load()
+	}
+
+	return nil
+}
+
 func Get(c Config, key string) interface{} {
 	return c.Get(key)
 }
 
 func GetBool(c Config, key string) bool {
-	return cast.ToBool(c.Get(key))
+	return Value[bool](c, key)
 }
 
 func GetInt(c Config, key string) int {
-	return cast.ToInt(c.Get(key))
+	return Value[int](c, key)
 }
 
 func GetInt32(c Config, key string) int32 {
-	return cast.ToInt32(c.Get(key))
+	return Value[int32](c, key)
 }
 
 func GetInt64(c Config, key string) int64 {
-	return cast.ToInt64(c.Get(key))
+	return Value[int64](c, key)
 }
 
 func GetIntSlice(c Config, key string) []int {
-	return cast.ToIntSlice(c.Get(key))
+	return Value[[]int](c, key)
 }
 
 func GetUint(c Config, key string) uint {
-	return cast.ToUint(c.Get(key))
+	return Value[uint](c, key)
 }
 
 func GetUint32(c Config, key string) uint32 {
-	return cast.ToUint32(c.Get(key))
+	return Value[uint32](c, key)
 }
 
 func GetUint64(c Config, key string) uint64 {
-	return cast.ToUint64(c.Get(key))
+	return Value[uint64](c, key)
 }
 
 func GetFloat32(c Config, key string) float32 {
-	return cast.ToFloat32(c.Get(key))
+	return Value[float32](c, key)
 }
 
 func GetFloat64(c Config, key string) float64 {
-	return cast.ToFloat64(c.Get(key))
+	return Value[float64](c, key)
 }
 
 func GetString(c Config, key string) string {
-	return cast.ToString(c.Get(key))
+	return Value[string](c, key)
 }
 
 func GetStringSlice(c Config, key string) []string {
-	return cast.ToStringSlice(c.Get(key))
+	return Value[[]string](c, key)
 }
 
 func GetTime(c Config, key string) time.Time {
-	return cast.ToTime(c.Get(key))
+	return Value[time.Time](c, key)
 }
 
 func GetDuration(c Config, key string) time.Duration {
-	return cast.ToDuration(c.Get(key))
+	return Value[time.Duration](c, key)
+}
+
+// Set sets value by key if c implements Writer.
+func Set(c Config, key string, value interface{}) error {
+	if w, ok := c.(Writer); ok {
+		return w.Set(key, value)
+	}
+
+	return ErrReadOnly
+}
+
+// Delete deletes value by key if c implements Writer.
+func Delete(c Config, key string) error {
+	if w, ok := c.(Writer); ok {
+		return w.Delete(key)
+	}
+
+	return ErrReadOnly
+}
+
+// Persist writes the modified data of c back to datasources if c implements
+// Writer.
+func Persist(c Config) error {
+	if w, ok := c.(Writer); ok {
+		return w.Persist()
+	}
+
+	return ErrReadOnly
 }
@@ -1,37 +1,117 @@
+// Package datasource loads the data of configurations from local files,
+// memory, etc.
+//
+// The datasource packages, e.g. localfile, register their builders into
+// DefaultRegistry while imported. They also export NewBuilder, so that the
+// builders could be registered into a Registry explicitly, e.g.
+//
+//	r := datasource.NewRegistry()
+//	r.Register(localfile.NewBuilder())
+//	c, err := config.New(t, config.WithDatasources(r))
 package datasource
 
 import (
 	"fmt"
-	"log"
+	"math"
+	"sync"
+	"time"
 
-	"errors"
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/k8s-practice/octopus/xlog"
+)
+
+const (
+	// KEY_VALIDATOR is the key of Target value which stores a Validator.
+	KEY_VALIDATOR = "validator"
+
+	// KEY_PARSERS is the key of Target value which stores a *parser.Registry.
+	KEY_PARSERS = "parsers"
+
+	// KEY_OPTIONAL is the key of Target value which stores a bool, the
+	// missing source of an optional target is loaded as empty data.
+	KEY_OPTIONAL = "optional"
+
+	// KEY_RETRY is the key of Target value which stores a *RetryPolicy.
+	KEY_RETRY = "retry"
+
+	// KEY_HISTORY is the key of Target value which stores the number of
+	// versions kept by the Versioned DataSource.
+	KEY_HISTORY = "history"
 )
 
 var (
-	// builders is a map from scheme to datasource builder.
-	builders = make(map[string]Builder)
+	// DefaultRegistry is the registry used by the package-level functions,
+	// the builtin datasources register themselves into it.
+	DefaultRegistry = NewRegistry()
 )
 
-// Register registers the datasource builder to the datasource map.
-func Register(b Builder) {
-	if _, ok := builders[b.Scheme()]; ok {
-		log.Panicf("Already regestered config scheme [%s].", b.Scheme())
+// Registry is a concurrent-safe map from scheme to datasource builder.
+type Registry struct {
+	mu       sync.RWMutex
+	builders map[string]Builder
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{builders: make(map[string]Builder)}
+}
+
+// Register registers the datasource builder, the builder registered before
+// for the same scheme is replaced.
+func (r *Registry) Register(b Builder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.builders[b.Scheme()]; ok {
+		xlog.Warnf("Replace registered datasource scheme [%s].", b.Scheme())
+	} else {
+		xlog.Infof("Register datasource scheme [%s].", b.Scheme())
 	}
+	r.builders[b.Scheme()] = b
+}
 
-	log.Printf("Register datasource scheme [%s].", b.Scheme())
-	builders[b.Scheme()] = b
+// Unregister removes the datasource builder of scheme.
+func (r *Registry) Unregister(scheme string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.builders, scheme)
 }
 
 // Build builds an object implements DataSource.
-func Build(t Target) (DataSource, error) {
-	builder, ok := builders[t.Scheme()]
+// The failed Build is retried by the RetryPolicy of t if present, the Load
+// is retried instead if the DataSource has been built.
+func (r *Registry) Build(t Target) (DataSource, error) {
+	r.mu.RLock()
+	builder, ok := r.builders[t.Scheme()]
+	r.mu.RUnlock()
 	if !ok {
-		return nil, errors.New(fmt.Sprintf("Unknown data source scheme [%s].", t.Scheme()))
+		return nil, fmt.Errorf("Unknown data source scheme [%s].", t.Scheme())
 	}
 
-	datasource, err := builder.Build(t)
+	var ds DataSource
+	err := RetryOf(t).Retry(fmt.Sprintf("build datasource [%s:%s]", t.Scheme(), t.Path()), func() (err error) {
+		if ds != nil {
+			return ds.Load()
+		}
+		ds, err = builder.Build(t)
+		return err
+	})
 
-	return datasource, err
+	return ds, err
+}
+
+// Register registers the datasource builder into DefaultRegistry.
+func Register(b Builder) {
+	DefaultRegistry.Register(b)
+}
+
+// Unregister removes the datasource builder of scheme from DefaultRegistry.
+func Unregister(scheme string) {
+	DefaultRegistry.Unregister(scheme)
+}
+
+// Build builds an object implements DataSource by DefaultRegistry.
+func Build(t Target) (DataSource, error) {
+	return DefaultRegistry.Build(t)
 }
 
 // DataSource load data from local file, etcd, consul, env variables, etc.
@@ -41,9 +121,127 @@ type DataSource interface {
 	Load() error
 
 	// Get returns the value stored in the path, or nil if no value is present.  // path (e.g. []string{"mysql", "addr"}) means finding "mysql.addr" in // datasource.
+	// An empty path returns the whole data.
 	Get(path []string) interface{}
 }
 
+// WritableDataSource is implemented by the DataSource which is able to
+// modify its data.
+type WritableDataSource interface {
+	DataSource
+
+	// Set sets value on the path, it takes effect immediately.
+	Set(path []string, value interface{}) error
+
+	// Delete deletes the value on the path, it takes effect immediately.
+	Delete(path []string) error
+
+	// Persist writes the data back to the storage of the datasource.
+	Persist() error
+}
+
+// Watcher is implemented by the DataSource which is able to notify
+// subscribers after its data changed.
+type Watcher interface {
+	// Watch registers f, f will be invoked after every successful Load.
+	Watch(f func())
+}
+
+// Stager is implemented by the DataSource which is able to read its data
+// without making it take effect, so that the data of several DataSources
+// is validated together before any of them takes effect, e.g. the layers
+// of config.MultiConfig.
+type Stager interface {
+	// Stage reads and validates the data like Load, the data takes effect
+	// after commit is called. The failure is recorded like Load.
+	Stage() (data map[string]interface{}, commit func(), err error)
+}
+
+// Validator validates the data loaded by DataSource before it takes effect,
+// the data fails to validate is discarded.
+type Validator func(data map[string]interface{}) error
+
+// ValidatorOf returns the Validator stored in t, or nil if not present.
+func ValidatorOf(t Target) Validator {
+	v, _ := t.Value(KEY_VALIDATOR).(Validator)
+	return v
+}
+
+// ParsersOf returns the parser registry stored in t, or
+// parser.DefaultRegistry if not present.
+func ParsersOf(t Target) *parser.Registry {
+	if r, ok := t.Value(KEY_PARSERS).(*parser.Registry); ok {
+		return r
+	}
+
+	return parser.DefaultRegistry
+}
+
+// IsOptional reports whether t is optional.
+func IsOptional(t Target) bool {
+	optional, _ := t.Value(KEY_OPTIONAL).(bool)
+	return optional
+}
+
+// RetryPolicy retries the failed Build and Load with exponential backoff.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts, including the first one.
+	Attempts int
+
+	// Backoff is the delay before the first retry, it doubles on every
+	// retry.
+	Backoff time.Duration
+
+	// MaxBackoff limits the delay if positive.
+	MaxBackoff time.Duration
+}
+
+// Delay returns the delay before the nth retry, n starts from 1. The delay
+// stops doubling at MaxBackoff, or at the maximum time.Duration.
+func (p *RetryPolicy) Delay(n int) time.Duration {
+	limit := p.MaxBackoff
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+
+	d := p.Backoff
+	for i := 1; i < n && d > 0 && d < limit; i++ {
+		if d > limit/2 {
+			d = limit
+		} else {
+			d *= 2
+		}
+	}
+	if d > limit {
+		d = limit
+	}
+
+	return d
+}
+
+// Retry calls f until it succeeds or the attempts run out, and returns the
+// last error. what describes f in the logs. A nil p calls f once.
+func (p *RetryPolicy) Retry(what string, f func() error) error {
+	err := f()
+	if p == nil {
+		return err
+	}
+
+	for i := 1; err != nil && i < p.Attempts; i++ {
+		xlog.Warnf("Failed to %s, retry %d: %v", what, i, err)
+		time.Sleep(p.Delay(i))
+		err = f()
+	}
+
+	return err
+}
+
+// RetryOf returns the RetryPolicy stored in t, or nil if not present.
+func RetryOf(t Target) *RetryPolicy {
+	p, _ := t.Value(KEY_RETRY).(*RetryPolicy)
+	return p
+}
+
 // Target helps to store the initialize data required by datasource.
 type Target interface {
 	// There must be scheme filed, otherwise how to find the datasource.
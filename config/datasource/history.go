@@ -0,0 +1,103 @@
+package datasource
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DEFAULT_HISTORY is the number of versions kept by default.
+const DEFAULT_HISTORY = 10
+
+// Version is a version of the data which took effect.
+type Version struct {
+	// ID increases from 1 in the order of versions.
+	ID int
+
+	// Time is when the version took effect.
+	Time time.Time
+
+	// Data is the whole data of the version, it must not be modified.
+	Data map[string]interface{}
+}
+
+// Versioned is implemented by the DataSource which keeps the versions of its
+// data, and is able to roll back.
+type Versioned interface {
+	// Versions returns the kept versions, the latest one is the current.
+	Versions() []Version
+
+	// Rollback makes the data of version id take effect again as a new
+	// version, the storage of the datasource is untouched.
+	Rollback(id int) error
+}
+
+// History helps DataSource to implement the interface of Versioned, it keeps
+// the last limit versions. The zero value keeps DEFAULT_HISTORY versions.
+type History struct {
+	mu       sync.Mutex
+	limit    int
+	next     int
+	versions []Version
+}
+
+// SetLimit keeps the last n versions, n less than 1 keeps the current only.
+func (h *History) SetLimit(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n < 1 {
+		n = 1
+	}
+	h.limit = n
+	h.trim()
+}
+
+// Add records data as the latest version.
+func (h *History) Add(data map[string]interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.next++
+	h.versions = append(h.versions, Version{ID: h.next, Time: time.Now(), Data: data})
+	h.trim()
+}
+
+func (h *History) trim() {
+	limit := h.limit
+	if limit == 0 {
+		limit = DEFAULT_HISTORY
+	}
+	if n := len(h.versions) - limit; n > 0 {
+		h.versions = append(h.versions[:0:0], h.versions[n:]...)
+	}
+}
+
+// Versions returns the kept versions in order.
+func (h *History) Versions() []Version {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]Version(nil), h.versions...)
+}
+
+// Version returns the kept version id.
+func (h *History) Version(id int) (Version, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, v := range h.versions {
+		if v.ID == id {
+			return v, nil
+		}
+	}
+
+	return Version{}, fmt.Errorf("Unknown version [%d].", id)
+}
+
+// HistoryOf returns the number of versions stored in t, or DEFAULT_HISTORY
+// if not present.
+func HistoryOf(t Target) int {
+	if n, ok := t.Value(KEY_HISTORY).(int); ok {
+		return n
+	}
+
+	return DEFAULT_HISTORY
+}
@@ -1,7 +1,11 @@
 package localfile
 
 import (
+	"errors"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sync"
 	"sync/atomic"
 
 	"github.com/k8s-practice/octopus/config/datasource"
@@ -14,22 +18,31 @@ const (
 )
 
 func init() {
-	datasource.Register(&builder{})
+	datasource.Register(NewBuilder())
 }
 
 func Scheme() string {
 	return scheme
 }
 
+// NewBuilder creates the builder of the local file datasource.
+func NewBuilder() datasource.Builder {
+	return &builder{}
+}
+
 type builder struct{}
 
 // Build builds a datasource.DataSource by datasource.Target.
 func (b *builder) Build(t datasource.Target) (datasource.DataSource, error) {
 	d := &localfile{
-		filepath: t.Path(),
-		format:   t.Format(),
+		filepath:  t.Path(),
+		format:    t.Format(),
+		validator: datasource.ValidatorOf(t),
+		parsers:   datasource.ParsersOf(t),
+		optional:  datasource.IsOptional(t),
 	}
 	d.config.Store(make(map[string]interface{}))
+	d.SetLimit(datasource.HistoryOf(t))
 
 	return d, d.Load()
 }
@@ -40,32 +53,132 @@ func (b *builder) Scheme() string {
 
 // localfile implements the interface of datasource.DataSource.
 type localfile struct {
+	// Notifier notifies watchers after reloading.
+	datasource.Notifier
+
+	// Recorder records the result of loading.
+	datasource.Recorder
+
+	// History keeps the versions of config.
+	datasource.History
+
 	// filepath is the path of the datasource file,
 	// absolute or relative path.
 	filepath string
 
 	// format is the format of the datasource file,
 	// it could be json, toml or yaml, etc.
+	// It's detected by the file extension or content if empty, and guarded
+	// by mu.
 	format string
 
+	// validator validates the data before it takes effect, could be nil.
+	validator datasource.Validator
+
+	// parsers parses and encodes the data.
+	parsers *parser.Registry
+
+	// optional loads the missing file as empty data.
+	optional bool
+
 	// config contains all configurations.
 	// Value store type is map[string]interface{}
 	config atomic.Value
+
+	// mu serializes modifications of config.
+	mu sync.Mutex
 }
 
+// Load reads and parses the file, the data loaded before is kept if it
+// fails. The result is recorded for Status.
 func (d *localfile) Load() error {
+	_, commit, err := d.Stage()
+	if err == nil {
+		commit()
+	}
+
+	return err
+}
+
+// Stage reads and parses the file, the data takes effect after commit.
+func (d *localfile) Stage() (map[string]interface{}, func(), error) {
+	config, err := d.load()
+	if err != nil {
+		return nil, nil, d.Record(err)
+	}
+
+	return config, func() {
+		d.Record(nil)
+		d.store(config)
+	}, nil
+}
+
+func (d *localfile) load() (map[string]interface{}, error) {
 	data, err := os.ReadFile(d.filepath)
+	if err != nil && d.optional && errors.Is(err, fs.ErrNotExist) {
+		return make(map[string]interface{}), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	format, err := d.detect(data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	config := make(map[string]interface{})
-	if err = parser.Parse(d.format, data, &config); err != nil {
-		return err
+	if err = d.parsers.Parse(format, data, &config); err != nil {
+		var pe *parser.ParseError
+		if errors.As(err, &pe) {
+			pe.Source = d.filepath
+		}
+		return nil, err
+	}
+	if d.validator != nil {
+		if err = d.validator(config); err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// detect returns the format of the file, it's detected from data by the
+// first load if absent.
+func (d *localfile) detect(data []byte) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.format == "" {
+		format, err := d.parsers.Detect(data, d.filepath)
+		if err != nil {
+			return "", err
+		}
+		d.format = format
 	}
+
+	return d.format, nil
+}
+
+// store makes config take effect as a new version, and notifies watchers.
+func (d *localfile) store(config map[string]interface{}) {
+	d.mu.Lock()
 	d.config.Store(config)
+	d.Add(config)
+	d.mu.Unlock()
+	d.Notify()
+}
 
-	return err
+// Rollback makes the data of version id take effect again, the file is not
+// modified until Persist.
+func (d *localfile) Rollback(id int) error {
+	v, err := d.Version(id)
+	if err != nil {
+		return err
+	}
+	d.store(v.Data)
+
+	return nil
 }
 
 func (d *localfile) Get(path []string) interface{} {
@@ -77,6 +190,87 @@ func (d *localfile) Get(path []string) interface{} {
 	return configsearch.SearchPathInMap(m.(map[string]interface{}), path)
 }
 
+// Set sets value on the path, the file is not modified until Persist.
+func (d *localfile) Set(path []string, value interface{}) error {
+	return d.modify(func(config map[string]interface{}) error {
+		return configsearch.SetValueInMap(config, path, value)
+	})
+}
+
+// Delete deletes the value on the path, the file is not modified until
+// Persist.
+func (d *localfile) Delete(path []string) error {
+	return d.modify(func(config map[string]interface{}) error {
+		return configsearch.DeleteValueInMap(config, path)
+	})
+}
+
+// modify applies f on a copy of config, the copy takes effect if f and
+// validator succeed.
+func (d *localfile) modify(f func(config map[string]interface{}) error) error {
+	d.mu.Lock()
+	config := configsearch.Copy(d.config.Load()).(map[string]interface{})
+	err := f(config)
+	if err == nil && d.validator != nil {
+		err = d.validator(config)
+	}
+	if err == nil {
+		d.config.Store(config)
+		d.Add(config)
+	}
+	d.mu.Unlock()
+
+	if err == nil {
+		d.Notify()
+	}
+
+	return err
+}
+
+// Persist encodes config in the format of the file, and replaces the file.
+func (d *localfile) Persist() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.format == "" {
+		// The optional file is missing, the format is decided by the
+		// extension.
+		format, err := d.parsers.Detect(nil, d.filepath)
+		if err != nil {
+			return err
+		}
+		d.format = format
+	}
+	data, err := d.parsers.Marshal(d.format, d.config.Load())
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(d.filepath); err == nil {
+		mode = fi.Mode()
+	}
+
+	// Write a temporary file and rename it, readers never see a partial file.
+	tmp, err := os.CreateTemp(filepath.Dir(d.filepath), filepath.Base(d.filepath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if e := tmp.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), d.filepath)
+}
+
 /*
 func (d *localfile) findConfigFile() (string, error) {
 	for _, dir := range d.dirs {
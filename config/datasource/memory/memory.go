@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/k8s-practice/octopus/config/datasource"
+	"github.com/k8s-practice/octopus/internal/configsearch"
+)
+
+const (
+	scheme = "memory"
+
+	// KEY_DATA is the key of Target value which stores the initial data,
+	// a map[string]interface{} or a struct (pointer). The fields of struct
+	// are named by the mapstructure tags, or the lowercased field names.
+	KEY_DATA = "data"
+)
+
+func init() {
+	datasource.Register(NewBuilder())
+}
+
+func Scheme() string {
+	return scheme
+}
+
+// NewBuilder creates the builder of the memory datasource.
+func NewBuilder() datasource.Builder {
+	return &builder{}
+}
+
+type builder struct{}
+
+// Build builds a datasource.DataSource by datasource.Target, the data is
+// copied from the value of KEY_DATA, e.g.
+//
+//	config.New(config.T().WithScheme(memory.Scheme()).WithValue(memory.KEY_DATA, defaults))
+func (b *builder) Build(t datasource.Target) (datasource.DataSource, error) {
+	config, err := toMap(t.Value(KEY_DATA))
+	if err != nil {
+		return nil, err
+	}
+
+	d := &memory{validator: datasource.ValidatorOf(t)}
+	if d.validator != nil {
+		if err = d.validator(config); err != nil {
+			return nil, err
+		}
+	}
+	d.SetLimit(datasource.HistoryOf(t))
+	d.config.Store(config)
+	d.Add(config)
+
+	return d, d.Load()
+}
+
+func (b *builder) Scheme() string {
+	return Scheme()
+}
+
+// memory implements the interface of datasource.WritableDataSource,
+// the data lives in memory only.
+type memory struct {
+	// Notifier notifies watchers after modifying.
+	datasource.Notifier
+
+	// Recorder records the result of loading.
+	datasource.Recorder
+
+	// History keeps the versions of config.
+	datasource.History
+
+	// validator validates the data before it takes effect, could be nil.
+	validator datasource.Validator
+
+	// config contains all configurations.
+	// Value store type is map[string]interface{}
+	config atomic.Value
+
+	// mu serializes modifications of config.
+	mu sync.Mutex
+}
+
+// Load does nothing, there is no storage to read from.
+func (d *memory) Load() error {
+	return d.Record(nil)
+}
+
+func (d *memory) Get(path []string) interface{} {
+	return configsearch.SearchPathInMap(d.config.Load().(map[string]interface{}), path)
+}
+
+// Set sets value on the path, it takes effect immediately.
+func (d *memory) Set(path []string, value interface{}) error {
+	return d.modify(func(config map[string]interface{}) error {
+		return configsearch.SetValueInMap(config, path, value)
+	})
+}
+
+// Delete deletes the value on the path, it takes effect immediately.
+func (d *memory) Delete(path []string) error {
+	return d.modify(func(config map[string]interface{}) error {
+		return configsearch.DeleteValueInMap(config, path)
+	})
+}
+
+// Rollback makes the data of version id take effect again.
+func (d *memory) Rollback(id int) error {
+	v, err := d.Version(id)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.config.Store(v.Data)
+	d.Add(v.Data)
+	d.mu.Unlock()
+	d.Notify()
+
+	return nil
+}
+
+// Persist does nothing, there is no storage to write to.
+func (d *memory) Persist() error {
+	return nil
+}
+
+// modify applies f on a copy of config, the copy takes effect if f and
+// validator succeed.
+func (d *memory) modify(f func(config map[string]interface{}) error) error {
+	d.mu.Lock()
+	config := configsearch.Copy(d.config.Load()).(map[string]interface{})
+	err := f(config)
+	if err == nil && d.validator != nil {
+		err = d.validator(config)
+	}
+	if err == nil {
+		d.config.Store(config)
+		d.Add(config)
+	}
+	d.mu.Unlock()
+
+	if err == nil {
+		d.Notify()
+	}
+
+	return err
+}
+
+// toMap converts data into map[string]interface{}, nil is converted into an
+// empty map.
+func toMap(data interface{}) (map[string]interface{}, error) {
+	if data == nil {
+		return make(map[string]interface{}), nil
+	}
+
+	m, ok := convert(reflect.ValueOf(data)).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Unsupported memory data type [%T].", data)
+	}
+
+	return m, nil
+}
+
+// convert converts structs and maps in v into map[string]interface{}, and
+// slices into []interface{} recursively, the other values are kept.
+func convert(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case !v.CanInterface():
+		// Reached through unexported embedded struct.
+		return nil
+	case v.Type() == configsearch.DurationType || v.Type() == configsearch.TimeType:
+		return v.Interface()
+	case v.Kind() == reflect.Struct:
+		m := make(map[string]interface{})
+		convertStruct(v, m)
+		return m
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		m := make(map[string]interface{}, v.Len())
+		for it := v.MapRange(); it.Next(); {
+			m[it.Key().String()] = convert(it.Value())
+		}
+		return m
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = convert(v.Index(i))
+		}
+		return s
+	default:
+		return v.Interface()
+	}
+}
+
+// convertStruct stores the exported fields of struct v into m, the embedded
+// structs are squashed.
+func convertStruct(v reflect.Value, m map[string]interface{}) {
+	for _, f := range configsearch.Fields(v.Type()) {
+		fv := v.FieldByIndex(f.Index)
+		for fv.Kind() == reflect.Ptr && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if f.Squash && fv.Kind() == reflect.Struct {
+			convertStruct(fv, m)
+			continue
+		}
+		m[f.Key] = convert(fv)
+	}
+}
@@ -0,0 +1,29 @@
+package datasource
+
+import "sync"
+
+// Notifier helps DataSource to implement the interface of Watcher.
+// The zero value is ready to use.
+type Notifier struct {
+	mu       sync.Mutex
+	watchers []func()
+}
+
+// Watch registers f to the notifier.
+func (n *Notifier) Watch(f func()) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.watchers = append(n.watchers, f)
+}
+
+// Notify invokes all registered watchers in registration order.
+func (n *Notifier) Notify() {
+	n.mu.Lock()
+	watchers := make([]func(), len(n.watchers))
+	copy(watchers, n.watchers)
+	n.mu.Unlock()
+
+	for _, f := range watchers {
+		f()
+	}
+}
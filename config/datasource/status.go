@@ -0,0 +1,71 @@
+package datasource
+
+import (
+	"sync"
+	"time"
+)
+
+// Status describes the health of a DataSource.
+type Status struct {
+	// LastError is the error of the last Load, nil if it succeeded.
+	LastError error
+
+	// LastLoad is the time of the last Load.
+	LastLoad time.Time
+
+	// LastSuccess is the time of the last successful Load, the data loaded
+	// then is still in effect if the later Loads failed.
+	LastSuccess time.Time
+
+	// Failures is the number of consecutive failed Loads.
+	Failures int
+}
+
+// Healthy reports whether the last Load succeeded.
+func (s Status) Healthy() bool {
+	return s.LastError == nil
+}
+
+// StatusReporter is implemented by the DataSource which records the result
+// of its Loads.
+type StatusReporter interface {
+	Status() Status
+}
+
+// Recorder helps DataSource to implement the interface of StatusReporter.
+// The zero value is ready to use.
+type Recorder struct {
+	mu     sync.Mutex
+	status Status
+}
+
+// Record records the result of a Load, and returns err.
+func (r *Recorder) Record(err error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.status.LastError = err
+	r.status.LastLoad = now
+	if err != nil {
+		r.status.Failures++
+	} else {
+		r.status.LastSuccess = now
+		r.status.Failures = 0
+	}
+
+	return err
+}
+
+// Status returns the recorded status.
+func (r *Recorder) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.status
+}
+
+// LastError returns the error of the last Load.
+func (r *Recorder) LastError() error {
+	return r.Status().LastError
+}
@@ -0,0 +1,280 @@
+// Package feature evaluates feature flags stored in config.Config.
+//
+// A flag is a bool, a rollout percentage, or a map with rules. The numeric
+// strings are rollout percentages as well, e.g. "1" is a 1% rollout rather
+// than true, and "0" disables the flag. The flags look like:
+//
+//	features:
+//	  new-ui: true
+//	  fast-path: 25
+//	  checkout-v2:
+//	    enabled: true
+//	    rollout: 10
+//	    bucket: tenant_id
+//	    rules:
+//	      - match: {region: [eu-west, eu-north]}
+//	        rollout: 50
+//	      - match: {tenant_id: "42"}
+//	        enabled: false
+//
+// The first rule whose match attributes all equal takes effect, the rule
+// without enabled or rollout inherits them from the flag. A rollout picks
+// the bucket of the flag by the hash of the bucket attribute, user_id by
+// default, so the same user always gets the same result.
+package feature
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/utils/cast"
+)
+
+const (
+	// DEFAULT_PREFIX is the key of flags in config by default.
+	DEFAULT_PREFIX = "features"
+
+	// The well-known attributes, the callers use them as the keys of
+	// Attributes, and the flags refer them in the bucket and match of rules.
+	// ATTR_USER is the bucket attribute by default.
+	ATTR_TENANT = "tenant_id"
+	ATTR_USER   = "user_id"
+	ATTR_REGION = "region"
+)
+
+// Attributes describes the subject of evaluation, e.g. the user.
+type Attributes map[string]string
+
+// Option configures the Flags created by New.
+type Option func(f *Flags)
+
+// WithPrefix reads flags under key prefix instead of DEFAULT_PREFIX.
+func WithPrefix(prefix string) Option {
+	return func(f *Flags) {
+		f.prefix = prefix
+	}
+}
+
+// Flags evaluates the flags stored in a config.Config, the flags are
+// refreshed every time the config reloads if it implements config.Watcher.
+type Flags struct {
+	c      config.Config
+	prefix string
+
+	// flags stores map[string]*flag.
+	flags atomic.Value
+}
+
+// New creates Flags reading c.
+func New(c config.Config, opts ...Option) *Flags {
+	f := &Flags{c: c, prefix: DEFAULT_PREFIX}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	f.load()
+	if w, ok := c.(config.Watcher); ok {
+		w.Watch(f.load)
+	}
+
+	return f
+}
+
+// load parses all flags, the invalid flags are disabled.
+func (f *Flags) load() {
+	flags := make(map[string]*flag)
+	m, _ := cast.ToStringMapE(f.c.Get(f.prefix))
+	for name, v := range m {
+		flags[name] = parseFlag(v)
+	}
+
+	f.flags.Store(flags)
+}
+
+// Names returns the sorted names of all flags.
+func (f *Flags) Names() []string {
+	flags := f.flags.Load().(map[string]*flag)
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Enabled reports whether flag name is enabled for attrs, the unknown flag
+// is disabled.
+func (f *Flags) Enabled(name string, attrs Attributes) bool {
+	fl, ok := f.flags.Load().(map[string]*flag)[name]
+	if !ok {
+		return false
+	}
+
+	return fl.enabled(name, attrs)
+}
+
+// flag is the parsed flag.
+type flag struct {
+	// on switches the flag.
+	on bool
+
+	// rollout is the percentage of buckets enabled, 100 for all.
+	rollout float64
+
+	// bucket is the attribute to pick the bucket.
+	bucket string
+
+	rules []rule
+}
+
+type rule struct {
+	// match maps the attribute to its accepted values.
+	match map[string][]string
+
+	// on and rollout override the flag if not nil.
+	on      *bool
+	rollout *float64
+}
+
+func parseFlag(v interface{}) *flag {
+	fl := &flag{rollout: 100, bucket: ATTR_USER}
+
+	switch v := v.(type) {
+	case bool:
+		fl.on = v
+		return fl
+	case string:
+		// The numeric strings are rollout percentages, e.g. "1" is 1% rather
+		// than true.
+		if p, err := cast.ToFloat64E(v); err == nil {
+			fl.on, fl.rollout = true, p
+			return fl
+		}
+		if b, err := cast.ToBoolE(v); err == nil {
+			fl.on = b
+			return fl
+		}
+	}
+
+	if p, err := cast.ToFloat64E(v); err == nil {
+		fl.on, fl.rollout = true, p
+		return fl
+	}
+
+	m, err := cast.ToStringMapE(v)
+	if err != nil {
+		return fl
+	}
+	fl.on = true
+	if x, ok := m["enabled"]; ok {
+		fl.on = cast.ToBool(x)
+	}
+	if x, ok := m["rollout"]; ok {
+		fl.rollout = cast.ToFloat64(x)
+	}
+	if x, ok := m["bucket"]; ok {
+		fl.bucket = cast.ToString(x)
+	}
+	rules, _ := m["rules"].([]interface{})
+	for _, r := range rules {
+		fl.rules = append(fl.rules, parseRule(r))
+	}
+
+	return fl
+}
+
+func parseRule(v interface{}) rule {
+	var r rule
+	m, err := cast.ToStringMapE(v)
+	if err != nil {
+		// Never matches.
+		r.match = map[string][]string{"": nil}
+		return r
+	}
+
+	match, _ := cast.ToStringMapE(m["match"])
+	r.match = make(map[string][]string, len(match))
+	for attr, x := range match {
+		if s, ok := x.([]interface{}); ok {
+			r.match[attr] = cast.ToStringSlice(s)
+		} else {
+			r.match[attr] = []string{cast.ToString(x)}
+		}
+	}
+	if x, ok := m["enabled"]; ok {
+		on := cast.ToBool(x)
+		r.on = &on
+	}
+	if x, ok := m["rollout"]; ok {
+		rollout := cast.ToFloat64(x)
+		r.rollout = &rollout
+	}
+
+	return r
+}
+
+func (fl *flag) enabled(name string, attrs Attributes) bool {
+	on, rollout := fl.on, fl.rollout
+	for _, r := range fl.rules {
+		if r.matches(attrs) {
+			if r.on != nil {
+				on = *r.on
+			}
+			if r.rollout != nil {
+				rollout = *r.rollout
+			}
+			break
+		}
+	}
+
+	switch {
+	case !on || rollout <= 0:
+		return false
+	case rollout >= 100:
+		return true
+	}
+
+	key, ok := attrs[fl.bucket]
+	if !ok {
+		return false
+	}
+
+	return Bucket(name, key) < rollout
+}
+
+// matches reports whether all match attributes of r equal to attrs.
+func (r *rule) matches(attrs Attributes) bool {
+	for attr, values := range r.match {
+		v, ok := attrs[attr]
+		if !ok || !contains(values, v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if strings.EqualFold(x, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Bucket returns the bucket of key in flag name, in [0, 100).
+// The bucket is stable, and independent between flags.
+func Bucket(name, key string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+
+	return float64(h.Sum32()%10000) / 100
+}
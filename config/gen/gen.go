@@ -0,0 +1,204 @@
+// Package gen generates Go structs mirroring configurations, the structs
+// carry mapstructure tags so that they could be loaded by Octopus.Load.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/k8s-practice/octopus/utils/cast"
+)
+
+// Generate generates the source of package pkg, which declares struct name
+// mirroring tree and the structs of the nested maps. The types of fields are
+// inferred from values, the strings like "5s" are time.Duration, and the
+// strings of dates are time.Time.
+func Generate(tree map[string]interface{}, pkg, name string) ([]byte, error) {
+	g := &generator{names: make(map[string]bool)}
+	g.structOf(exported(name), tree)
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by octopus-config gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	if g.time {
+		b.WriteString("import \"time\"\n\n")
+	}
+	for _, decl := range g.decls {
+		b.WriteString(decl)
+		b.WriteString("\n")
+	}
+
+	return format.Source(b.Bytes())
+}
+
+type generator struct {
+	// names are the declared type names.
+	names map[string]bool
+
+	// decls are the struct declarations in order.
+	decls []string
+
+	// time reports whether package time is used.
+	time bool
+}
+
+// structOf declares a struct named name for m, returns the declared name,
+// which is prefixed by "X" until unique.
+func (g *generator) structOf(name string, m map[string]interface{}) string {
+	for g.names[name] {
+		name = "X" + name
+	}
+	g.names[name] = true
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Reserves the position, nested structs are declared after it.
+	i := len(g.decls)
+	g.decls = append(g.decls, "")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	fields := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		field := exported(k)
+		for fields[field] {
+			field += "_"
+		}
+		fields[field] = true
+		fmt.Fprintf(&b, "\t%s %s `mapstructure:%q`\n", field, g.typeOf(name+exported(k), m[k]), k)
+	}
+	b.WriteString("}\n")
+	g.decls[i] = b.String()
+
+	return name
+}
+
+// typeOf returns the type of v, the nested struct is named name.
+func (g *generator) typeOf(name string, v interface{}) string {
+	switch v := v.(type) {
+	case bool:
+		return "bool"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "int"
+	case float32, float64:
+		if f := cast.ToFloat64(v); f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			return "int"
+		}
+		return "float64"
+	case time.Time:
+		g.time = true
+		return "time.Time"
+	case time.Duration:
+		g.time = true
+		return "time.Duration"
+	case string:
+		return g.stringType(v)
+	case map[string]interface{}:
+		return g.structOf(name, v)
+	case []interface{}:
+		return "[]" + g.elemType(name, v)
+	default:
+		return "interface{}"
+	}
+}
+
+// stringType infers the type of string s.
+func (g *generator) stringType(s string) string {
+	if strings.IndexFunc(s, unicode.IsLetter) >= 0 && strings.IndexFunc(s, unicode.IsDigit) == 0 {
+		if _, err := time.ParseDuration(s); err == nil {
+			g.time = true
+			return "time.Duration"
+		}
+	}
+	if _, err := cast.StringToDate(s); err == nil && strings.IndexFunc(s, unicode.IsDigit) >= 0 {
+		g.time = true
+		return "time.Time"
+	}
+
+	return "string"
+}
+
+// elemType returns the type of elements of s. The maps are merged into one
+// struct, the elements of different types are interface{}.
+func (g *generator) elemType(name string, s []interface{}) string {
+	if len(s) == 0 {
+		return "interface{}"
+	}
+
+	merged := make(map[string]interface{})
+	maps := 0
+	for _, v := range s {
+		if m, ok := v.(map[string]interface{}); ok {
+			maps++
+			for k, x := range m {
+				if _, ok := merged[k]; !ok {
+					merged[k] = x
+				}
+			}
+		}
+	}
+	switch {
+	case maps == len(s):
+		return g.structOf(name, merged)
+	case maps > 0:
+		return "interface{}"
+	}
+
+	// Infers the types without declaring, since there is no struct.
+	probe := &generator{names: make(map[string]bool)}
+	types := make(map[string]bool)
+	for _, v := range s {
+		types[probe.typeOf(name, v)] = true
+	}
+	g.time = g.time || probe.time
+
+	switch {
+	case len(probe.decls) > 0:
+		// Nested slices of maps.
+		return "interface{}"
+	case len(types) == 1:
+		for t := range types {
+			return t
+		}
+	case len(types) == 2 && types["int"] && types["float64"]:
+		return "float64"
+	}
+
+	return "interface{}"
+}
+
+// exported converts key into an exported Go identifier, e.g. "db_name" and
+// "db-name" become "DbName".
+func exported(key string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range key {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			b.WriteRune(r)
+		default:
+			upper = true
+		}
+	}
+
+	s := b.String()
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		s = "X" + s
+	}
+
+	return s
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/k8s-practice/octopus/config/datasource"
+	"github.com/k8s-practice/octopus/internal/configsearch"
 	"github.com/k8s-practice/octopus/utils/cast"
 )
 
@@ -15,46 +16,226 @@ const (
 	KEY_PATH   = "path"
 )
 
-var (
-	// delim separates the nested key.
-	delim string = DEFAULT_KEY_DELIMITER
-)
-
 func T() datasource.Target {
 	return make(target)
 }
 
-func New(t datasource.Target) (Config, error) {
-	if ds, err := datasource.Build(t); err != nil {
+// New creates a Config reading data from the datasource described by t,
+// the options are stored into a copy of t.
+func New(t datasource.Target, opts ...Option) (Config, error) {
+	o := &options{delim: DEFAULT_KEY_DELIMITER, datasources: datasource.DefaultRegistry}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	t = cloneTarget(t)
+	if o.schema != nil {
+		t.WithValue(datasource.KEY_VALIDATOR, validator(o.schema, o.layer))
+	}
+
+	if o.parsers != nil {
+		t.WithValue(datasource.KEY_PARSERS, o.parsers)
+	}
+	if o.optional {
+		t.WithValue(datasource.KEY_OPTIONAL, true)
+	}
+	if o.retry != nil {
+		t.WithValue(datasource.KEY_RETRY, o.retry)
+	}
+	if o.history > 0 {
+		t.WithValue(datasource.KEY_HISTORY, o.history)
+	}
+
+	if ds, err := o.datasources.Build(t); err != nil {
 		return nil, err
 	} else {
-		return &config{ds: ds}, nil
+		return &config{
+			ds:     ds,
+			delim:  o.delim,
+			fold:   o.fold,
+			schema: o.schema,
+			retry:  o.retry,
+			source: t.Scheme() + ":" + t.Path(),
+		}, nil
 	}
 }
 
+// validator validates the data loaded by the datasource with s, the required
+// keys are checked too unless the datasource is a layer.
+func validator(s *Schema, layer bool) datasource.Validator {
+	if layer {
+		return s.ValidateLayer
+	}
+
+	return func(m map[string]interface{}) error {
+		if err := s.ValidateLayer(m); err != nil {
+			return err
+		}
+		return s.Validate(&config{ds: static(m), delim: DEFAULT_KEY_DELIMITER})
+	}
+}
+
+// cloneTarget copies t, New stores values into the target.
+// Only the scheme, format and path are copied if t is not created by T.
+func cloneTarget(t datasource.Target) datasource.Target {
+	if src, ok := t.(target); ok {
+		dst := make(target, len(src))
+		for k, v := range src {
+			dst[k] = v
+		}
+		return dst
+	}
+
+	return T().WithScheme(t.Scheme()).WithFormat(t.Format()).WithPath(t.Path())
+}
+
 // config implements the interface of Config.
 type config struct {
 	ds datasource.DataSource
+
+	// delim separates the nested key.
+	delim string
+
+	// fold matches keys case-insensitively.
+	fold bool
+
+	// schema validates the data of ds, it also validates the MultiConfig
+	// combining the config.
+	schema *Schema
+
+	// retry retries the failed Load, could be nil.
+	retry *datasource.RetryPolicy
+
+	// source describes the datasource in the logs, e.g. "localfile:app.yaml".
+	source string
 }
 
-// Get gets value by key, it's thread safe.
+// Get gets value by key, it's thread safe. The maps and slices are deeply
+// copied, so that modifying the value never changes the data of c.
 func (c *config) Get(key string) interface{} {
-	if v := c.ds.Get([]string{key}); v != nil {
-		return v
+	if v := c.search([]string{key}); v != nil {
+		return configsearch.Copy(v)
 	}
 
-	path := strings.Split(key, delim)
-	if len(path) == 1 {
+	path := configsearch.SplitKey(key, c.delim)
+	if len(path) == 1 && path[0] == key {
 		return nil
 	}
 
+	if v := c.search(path); v != nil {
+		return configsearch.Copy(v)
+	}
+
+	return nil
+}
+
+func (c *config) search(path []string) interface{} {
+	if !c.fold {
+		return c.ds.Get(path)
+	}
+
 	if v := c.ds.Get(path); v != nil {
 		return v
 	}
 
+	m, ok := c.ds.Get(nil).(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	return configsearch.SearchPathInMapFold(m, path)
+}
+
+// Load reloads the datasource, the failed Load is retried by the
+// RetryPolicy given by WithRetry.
+func (c *config) Load() error {
+	return c.retry.Retry("load datasource ["+c.source+"]", c.ds.Load)
+}
+
+// stage reads the data of the datasource without making it take effect if
+// the datasource is a datasource.Stager, the candidate Config serves the
+// data until commit. Otherwise the datasource is loaded directly, and c is
+// the candidate. c is the candidate if it fails.
+func (c *config) stage() (candidate Config, commit func(), err error) {
+	st, ok := c.ds.(datasource.Stager)
+	if !ok {
+		return c, func() {}, c.Load()
+	}
+
+	var data map[string]interface{}
+	err = c.retry.Retry("load datasource ["+c.source+"]", func() (err error) {
+		data, commit, err = st.Stage()
+		return err
+	})
+	if err != nil {
+		return c, func() {}, err
+	}
+
+	return &config{ds: static(data), delim: c.delim, fold: c.fold}, commit, nil
+}
+
+// Watch registers f to the datasource if it supports watching.
+func (c *config) Watch(f func()) {
+	if w, ok := c.ds.(datasource.Watcher); ok {
+		w.Watch(f)
+	}
+}
+
+// Set sets value by key if the datasource is writable.
+func (c *config) Set(key string, value interface{}) error {
+	if w, ok := c.ds.(datasource.WritableDataSource); ok {
+		return w.Set(configsearch.SplitKey(key, c.delim), value)
+	}
+
+	return ErrReadOnly
+}
+
+// Delete deletes value by key if the datasource is writable.
+func (c *config) Delete(key string) error {
+	if w, ok := c.ds.(datasource.WritableDataSource); ok {
+		return w.Delete(configsearch.SplitKey(key, c.delim))
+	}
+
+	return ErrReadOnly
+}
+
+// Persist writes data back to the datasource if it's writable.
+func (c *config) Persist() error {
+	if w, ok := c.ds.(datasource.WritableDataSource); ok {
+		return w.Persist()
+	}
+
+	return ErrReadOnly
+}
+
+// Status returns the status of the datasource, the datasource doesn't record
+// its status is reported as healthy.
+func (c *config) Status() []datasource.Status {
+	if r, ok := c.ds.(datasource.StatusReporter); ok {
+		return []datasource.Status{r.Status()}
+	}
+
+	return []datasource.Status{{}}
+}
+
+// Versions returns the kept versions of the datasource.
+func (c *config) Versions() []datasource.Version {
+	if v, ok := c.ds.(datasource.Versioned); ok {
+		return v.Versions()
+	}
+
 	return nil
 }
 
+// Rollback rolls back the datasource to version id.
+func (c *config) Rollback(id int) error {
+	if v, ok := c.ds.(datasource.Versioned); ok {
+		return v.Rollback(id)
+	}
+
+	return ErrNoHistory
+}
+
 // target implements the interface of datasource.Target.
 type target map[string]interface{}
 
@@ -85,7 +266,7 @@ func (t target) Format() string {
 }
 
 func (t target) WithPath(path string) datasource.Target {
-	t[KEY_PATH] = strings.ToLower(path)
+	t[KEY_PATH] = path
 	return t
 }
 
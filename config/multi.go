@@ -1,11 +1,18 @@
 package config
 
+import (
+	"github.com/k8s-practice/octopus/config/datasource"
+	"github.com/k8s-practice/octopus/internal/configsearch"
+)
+
 type multiConfig struct {
 	allConfig []Config
 }
 
 // MultiConfig combines configurations.
 // Config order is the priority of each configurations.
+// The Schemas of the layers created by New validate the combined
+// configurations after every Load, see WithLayerSchema.
 func MultiConfig(configSlice ...Config) Config {
 	allConfig := make([]Config, 0, len(configSlice))
 	for _, c := range configSlice {
@@ -19,12 +26,178 @@ func MultiConfig(configSlice ...Config) Config {
 	return &multiConfig{allConfig}
 }
 
+// Get gets value by key from the configuration with the highest priority
+// which has the key. The maps are deeply merged by the priorities like
+// Snapshot, so that a layer overriding part of a section keeps the rest.
 func (mc *multiConfig) Get(key string) interface{} {
+	return mc.get(key, Config.Get)
+}
+
+// Peek gets value like Get without recording by the layers which record
+// the keys read, e.g. Auditor.
+func (mc *multiConfig) Peek(key string) interface{} {
+	return mc.get(key, peek)
+}
+
+// Record forwards the record to the layers which record the keys read.
+func (mc *multiConfig) Record(key string, found bool) {
+	for _, c := range mc.allConfig {
+		if r, ok := c.(ReadRecorder); ok {
+			r.Record(key, found)
+		}
+	}
+}
+
+func (mc *multiConfig) get(key string, get func(c Config, key string) interface{}) interface{} {
+	values := make([]interface{}, 0, len(mc.allConfig))
 	for _, c := range mc.allConfig {
-		if i := c.Get(key); i != nil {
-			return i
+		if i := get(c, key); i != nil {
+			if _, ok := i.(map[string]interface{}); !ok && len(values) == 0 {
+				return i
+			}
+			values = append(values, i)
+		}
+	}
+
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	}
+
+	var merged interface{}
+	for i := len(values) - 1; i >= 0; i-- {
+		merged = configsearch.Merge(merged, configsearch.Copy(values[i]))
+	}
+
+	return merged
+}
+
+// Load reloads all configurations, the new data takes effect only if the
+// combined configurations pass the Schemas of the layers, otherwise all
+// layers keep their data. The layer fails to reload keeps its data. Only
+// the layers created by New hold the new data back, the others take effect
+// on reloading. Returns the first error.
+func (mc *multiConfig) Load() error {
+	var err error
+	candidates := make([]Config, len(mc.allConfig))
+	commits := make([]func(), len(mc.allConfig))
+	for i, c := range mc.allConfig {
+		var e error
+		if candidates[i], commits[i], e = stage(c); e != nil && err == nil {
+			err = e
+		}
+	}
+
+	if e := mc.validate(&multiConfig{candidates}); e != nil {
+		return e
+	}
+	for _, commit := range commits {
+		commit()
+	}
+
+	return err
+}
+
+// stage reads the data of c without making it take effect if possible, see
+// config.stage.
+func stage(c Config) (Config, func(), error) {
+	switch c := c.(type) {
+	case *config:
+		return c.stage()
+	case *Auditor:
+		return stage(c.c)
+	}
+
+	return c, func() {}, Reload(c)
+}
+
+// validate validates c, the combination of the layers of mc, with the
+// Schemas of the layers.
+func (mc *multiConfig) validate(c Config) error {
+	seen := make(map[*Schema]bool)
+	for _, layer := range mc.allConfig {
+		s := schemaOf(layer)
+		if s == nil || seen[s] {
+			continue
+		}
+		seen[s] = true
+		if err := s.Validate(c); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
+
+// schemaOf returns the Schema of the layer c created by New.
+func schemaOf(c Config) *Schema {
+	switch c := c.(type) {
+	case *config:
+		return c.schema
+	case *Auditor:
+		return schemaOf(c.c)
+	}
+
+	return nil
+}
+
+// Watch registers f to all configurations which support watching.
+func (mc *multiConfig) Watch(f func()) {
+	for _, c := range mc.allConfig {
+		if w, ok := c.(Watcher); ok {
+			w.Watch(f)
+		}
+	}
+}
+
+// Status returns the status of all configurations in priority order.
+func (mc *multiConfig) Status() []datasource.Status {
+	var status []datasource.Status
+	for _, c := range mc.allConfig {
+		status = append(status, Status(c)...)
+	}
+
+	return status
+}
+
+// Set sets value on the configuration with the highest priority which is
+// writable, so that the value takes effect.
+func (mc *multiConfig) Set(key string, value interface{}) error {
+	for _, c := range mc.allConfig {
+		if err := Set(c, key, value); err != ErrReadOnly {
+			return err
+		}
+	}
+
+	return ErrReadOnly
+}
+
+// Delete deletes value from all writable configurations, otherwise the value
+// in lower priority configurations takes effect.
+func (mc *multiConfig) Delete(key string) error {
+	return mc.writeAll(func(c Config) error { return Delete(c, key) })
+}
+
+// Persist persists all writable configurations.
+func (mc *multiConfig) Persist() error {
+	return mc.writeAll(Persist)
+}
+
+// writeAll applies f to all configurations, the read-only ones are skipped.
+// Returns ErrReadOnly if all configurations are read-only.
+func (mc *multiConfig) writeAll(f func(c Config) error) error {
+	err := ErrReadOnly
+	for _, c := range mc.allConfig {
+		switch e := f(c); {
+		case e == ErrReadOnly:
+		case e != nil:
+			return e
+		default:
+			err = nil
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"time"
+
+	"github.com/k8s-practice/octopus/config/datasource"
+	"github.com/k8s-practice/octopus/config/parser"
+)
+
+// Option configures the Config created by New.
+type Option func(o *options)
+
+// options contains all options of Config.
+type options struct {
+	// delim separates the nested key, DEFAULT_KEY_DELIMITER by default.
+	delim string
+
+	// fold matches keys case-insensitively.
+	fold bool
+
+	// schema validates the data of the datasource on every load.
+	schema *Schema
+
+	// layer validates the data as a layer of MultiConfig, the required keys
+	// are not checked.
+	layer bool
+
+	// datasources builds the datasource, datasource.DefaultRegistry by default.
+	datasources *datasource.Registry
+
+	// parsers parses the data of the datasource, parser.DefaultRegistry by
+	// default.
+	parsers *parser.Registry
+
+	// optional loads the missing datasource as empty data.
+	optional bool
+
+	// retry retries the failed datasource building and loading.
+	retry *datasource.RetryPolicy
+
+	// history is the number of versions kept by the datasource, 0 means
+	// datasource.DEFAULT_HISTORY.
+	history int
+}
+
+// WithSchema validates the data of the datasource with s while creating and
+// reloading, the data violates s is rejected. All required keys must be
+// present, see Schema.Validate.
+func WithSchema(s *Schema) Option {
+	return func(o *options) {
+		o.schema, o.layer = s, false
+	}
+}
+
+// WithLayerSchema validates the data of the datasource as a layer of
+// MultiConfig, see Schema.ValidateLayer. The required keys are checked by
+// the MultiConfig combining the layer on every reload.
+func WithLayerSchema(s *Schema) Option {
+	return func(o *options) {
+		o.schema, o.layer = s, true
+	}
+}
+
+// WithDelimiter separates the nested key with delim instead of
+// DEFAULT_KEY_DELIMITER. The delimiter in key could be escaped by a
+// backslash, e.g. `hosts.a\.b\.com.port`.
+func WithDelimiter(delim string) Option {
+	return func(o *options) {
+		o.delim = delim
+	}
+}
+
+// WithCaseInsensitive matches keys case-insensitively,
+// the exactly matched key takes precedence.
+func WithCaseInsensitive() Option {
+	return func(o *options) {
+		o.fold = true
+	}
+}
+
+// WithDatasources builds the datasource by r instead of
+// datasource.DefaultRegistry.
+func WithDatasources(r *datasource.Registry) Option {
+	return func(o *options) {
+		o.datasources = r
+	}
+}
+
+// WithParsers parses the data of the datasource by r instead of
+// parser.DefaultRegistry.
+func WithParsers(r *parser.Registry) Option {
+	return func(o *options) {
+		o.parsers = r
+	}
+}
+
+// WithOptional loads the missing datasource, e.g. a missing file, as empty
+// data rather than failing.
+func WithOptional() Option {
+	return func(o *options) {
+		o.optional = true
+	}
+}
+
+// WithRetry retries the failed datasource building and reloading up to
+// attempts times, the delay starts from backoff and doubles on every retry,
+// up to maxBackoff if positive.
+func WithRetry(attempts int, backoff, maxBackoff time.Duration) Option {
+	return func(o *options) {
+		o.retry = &datasource.RetryPolicy{Attempts: attempts, Backoff: backoff, MaxBackoff: maxBackoff}
+	}
+}
+
+// WithHistory keeps the last n versions of the datasource for Rollback
+// instead of datasource.DEFAULT_HISTORY.
+func WithHistory(n int) Option {
+	return func(o *options) {
+		o.history = n
+	}
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Sniffer reports whether data looks like the format of the parser.
+// It's optional for Builder, the Builder implements it is asked before
+// trial parsing, it helps the formats which are hard to tell by parsing.
+type Sniffer interface {
+	Sniff(data []byte) bool
+}
+
+// precedence is the order to sniff the builtin formats, the stricter
+// formats go first, since the looser ones accept most of the others.
+var precedence = []string{"json", "toml", "hcl", "yaml", "json5", "ini", "properties", "env"}
+
+// Detect detects the format of data. hint is a format or a file path, the
+// format or the file extension is used if it's registered. Otherwise the
+// Sniffers are asked, then data is parsed by the registered parsers in order
+// of precedence, the first one parsing data into a non-empty map wins.
+func (r *Registry) Detect(data []byte, hint string) (string, error) {
+	if hint != "" {
+		if _, ok := r.Lookup(hint); ok {
+			return strings.ToLower(hint), nil
+		}
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(hint), "."))
+		if _, ok := r.Lookup(ext); ext != "" && ok {
+			return ext, nil
+		}
+	}
+
+	formats, builders := r.sniffOrder()
+	for i, format := range formats {
+		if s, ok := builders[i].(Sniffer); ok && s.Sniff(data) {
+			return format, nil
+		}
+	}
+	for i, format := range formats {
+		if _, ok := builders[i].(Sniffer); ok {
+			continue
+		}
+		m := make(map[string]interface{})
+		if err := builders[i].Build().Parse(data, &m); err == nil && len(m) > 0 {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("Unknown format of [%s].", hint)
+}
+
+// Detect detects the format of data by DefaultRegistry.
+func Detect(data []byte, hint string) (string, error) {
+	return DefaultRegistry.Detect(data, hint)
+}
+
+// sniffOrder returns the registered formats and their builders in order of
+// precedence, the formats not in precedence are sorted after. The aliases
+// are skipped.
+func (r *Registry) sniffOrder() ([]string, []Builder) {
+	rank := func(format string) int {
+		for i, f := range precedence {
+			if f == format {
+				return i
+			}
+		}
+		return len(precedence)
+	}
+
+	formats := r.Formats()
+	sort.SliceStable(formats, func(i, j int) bool {
+		return rank(formats[i]) < rank(formats[j])
+	})
+
+	seen := make(map[Builder]bool, len(formats))
+	uniq := formats[:0]
+	builders := make([]Builder, 0, len(formats))
+	for _, format := range formats {
+		if b, ok := r.Lookup(format); ok && !seen[b] {
+			seen[b] = true
+			uniq = append(uniq, format)
+			builders = append(builders, b)
+		}
+	}
+
+	return uniq, builders
+}
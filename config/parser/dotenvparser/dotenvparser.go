@@ -0,0 +1,126 @@
+package dotenvparser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/k8s-practice/octopus/internal/configsearch"
+)
+
+const (
+	format       = "env"
+	format_alias = "dotenv"
+
+	// delim separates the nested keys.
+	delim = "."
+)
+
+func Format() string {
+	return format
+}
+
+func IsMatchFormat(fmt string) bool {
+	return fmt == format || fmt == format_alias
+}
+
+// NewBuilder creates the builder of the dotenv parser.
+func NewBuilder() parser.Builder {
+	return &builder{}
+}
+
+type builder struct{}
+
+func (b *builder) Format() []string {
+	return []string{format, format_alias}
+}
+
+func (b *builder) Build() parser.Parser {
+	return &dotenvParser{}
+}
+
+type dotenvParser struct{}
+
+// Parse parses .env data, each line is "KEY=value" and optionally prefixed
+// with "export". Double quoted values support escapes like "\n", single
+// quoted values are literal, the unquoted values could be followed by a
+// comment. Dotted keys become nested paths. All values are strings.
+func (p *dotenvParser) Parse(data []byte, v interface{}) error {
+	m := make(map[string]interface{})
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+			line = strings.TrimSpace(line[len("export"):])
+		}
+
+		sep := strings.IndexByte(line, '=')
+		if sep <= 0 {
+			return parser.NewParseError(lineno, 0, fmt.Errorf("expected KEY=value, got %q", line))
+		}
+		key := strings.TrimSpace(line[:sep])
+		if strings.ContainsAny(key, " \t") {
+			return parser.NewParseError(lineno, 0, fmt.Errorf("invalid key %q", key))
+		}
+		value, err := unquote(strings.TrimSpace(line[sep+1:]))
+		if err != nil {
+			return parser.NewParseError(lineno, 0, err)
+		}
+
+		if err = configsearch.InsertValueInMap(m, strings.Split(key, delim), value); err != nil {
+			return parser.NewParseError(lineno, 0, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return parser.Assign(m, v)
+}
+
+// unquote unquotes the quoted value, or strips the comment of the unquoted
+// value.
+func unquote(s string) (string, error) {
+	if s == "" {
+		return s, nil
+	}
+
+	switch s[0] {
+	case '"':
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				return strconv.Unquote(s[:i+1])
+			}
+		}
+		return "", fmt.Errorf("unterminated quoted value %s", s)
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", s)
+		}
+		return s[1 : end+1], nil
+	}
+
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, "\t#"); i >= 0 {
+		s = s[:i]
+	}
+
+	return strings.TrimSpace(s), nil
+}
+
+func init() {
+	parser.Register(NewBuilder())
+}
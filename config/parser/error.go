@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseError describes where the data fails to parse.
+type ParseError struct {
+	// Format is the format of the data.
+	Format string
+
+	// Source is the path of the data, e.g. the file path, set by datasource.
+	Source string
+
+	// Line and Column are 1-based position of the error, 0 if unknown.
+	Line   int
+	Column int
+
+	// Snippet is the line of the data where the error is.
+	Snippet string
+
+	Err error
+}
+
+// NewParseError creates a ParseError at line and column, Parse fills the
+// format and snippet.
+func NewParseError(line, column int, err error) *ParseError {
+	return &ParseError{Line: line, Column: column, Err: err}
+}
+
+// NewOffsetError creates a ParseError at the byte offset of data.
+func NewOffsetError(data []byte, offset int, err error) *ParseError {
+	line, column := Position(data, offset)
+	return NewParseError(line, column, err)
+}
+
+// asParseError converts err into ParseError, and completes the format and
+// snippet.
+func asParseError(format string, data []byte, err error) *ParseError {
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		pe = &ParseError{Err: err}
+	}
+
+	pe.Format = strings.ToLower(format)
+	if pe.Snippet == "" {
+		pe.Snippet = snippet(data, pe.Line)
+	}
+
+	return pe
+}
+
+// Position converts the byte offset of data into 1-based line and column.
+func Position(data []byte, offset int) (line, column int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	line = 1 + bytes.Count(data[:offset], []byte("\n"))
+	column = offset - bytes.LastIndexByte(data[:offset], '\n')
+
+	return line, column
+}
+
+func snippet(data []byte, line int) string {
+	if line <= 0 {
+		return ""
+	}
+
+	for i := 1; i < line; i++ {
+		next := bytes.IndexByte(data, '\n')
+		if next < 0 {
+			return ""
+		}
+		data = data[next+1:]
+	}
+	if end := bytes.IndexByte(data, '\n'); end >= 0 {
+		data = data[:end]
+	}
+
+	return strings.TrimRight(string(data), "\r")
+}
+
+// Error formats the error like:
+//
+//	Failed to parse yaml [./p2.yaml] at line 3, column 3: some error.
+//	    3 |   addr: b
+//	      |   ^
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	b.WriteString("Failed to parse")
+	if e.Format != "" {
+		b.WriteString(" " + e.Format)
+	}
+	if e.Source != "" {
+		b.WriteString(" [" + e.Source + "]")
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&b, " at line %d", e.Line)
+		if e.Column > 0 {
+			fmt.Fprintf(&b, ", column %d", e.Column)
+		}
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+
+	if e.Snippet != "" {
+		prefix := fmt.Sprintf("    %d | ", e.Line)
+		fmt.Fprintf(&b, "\n%s%s", prefix, e.Snippet)
+		if e.Column > 0 && e.Column <= len(e.Snippet)+1 {
+			// Keep tabs in the snippet to align the caret.
+			indent := strings.Map(func(r rune) rune {
+				if r == '\t' {
+					return r
+				}
+				return ' '
+			}, e.Snippet[:e.Column-1])
+			fmt.Fprintf(&b, "\n%*s| %s^", len(prefix)-2, "", indent)
+		}
+	}
+
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,125 @@
+package hclparser
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/hcl/ast"
+	hcl "github.com/hashicorp/hcl/hcl/parser"
+	"github.com/hashicorp/hcl/hcl/token"
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/k8s-practice/octopus/internal/configsearch"
+)
+
+const (
+	format = "hcl"
+)
+
+func Format() string {
+	return format
+}
+
+func IsMatchFormat(fmt string) bool {
+	return fmt == format
+}
+
+// NewBuilder creates the builder of the HCL parser.
+func NewBuilder() parser.Builder {
+	return &builder{}
+}
+
+type builder struct{}
+
+func (b *builder) Format() []string {
+	return []string{format}
+}
+
+func (b *builder) Build() parser.Parser {
+	return &hclParser{}
+}
+
+type hclParser struct{}
+
+// Parse parses HCL data. Blocks with labels become nested maps, e.g.
+// `service "web" { port = 80 }` is the same as `service { web { port = 80 } }`,
+// and the repeated blocks are merged.
+func (p *hclParser) Parse(data []byte, v interface{}) error {
+	f, err := hcl.Parse(data)
+	if e, ok := err.(*hcl.PosError); ok {
+		return posError(e.Pos, "%v", e.Err)
+	} else if err != nil {
+		return err
+	}
+
+	m := make(map[string]interface{})
+	if list, ok := f.Node.(*ast.ObjectList); ok {
+		if err = convertList(m, list); err != nil {
+			return err
+		}
+	}
+
+	return parser.Assign(m, v)
+}
+
+// convertList converts items of list into m.
+func convertList(m map[string]interface{}, list *ast.ObjectList) error {
+	for _, item := range list.Items {
+		value, err := convert(item.Val)
+		if err != nil {
+			return err
+		}
+
+		if len(item.Keys) == 0 {
+			return posError(item.Val.Pos(), "object item without key")
+		}
+		// Wraps value with the labels of block, from inner to outer.
+		for i := len(item.Keys) - 1; i > 0; i-- {
+			value = map[string]interface{}{keyOf(item.Keys[i]): value}
+		}
+
+		key := keyOf(item.Keys[0])
+		m[key] = configsearch.Merge(m[key], value)
+	}
+
+	return nil
+}
+
+func convert(n ast.Node) (interface{}, error) {
+	switch n := n.(type) {
+	case *ast.ObjectType:
+		m := make(map[string]interface{})
+		if err := convertList(m, n.List); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case *ast.ListType:
+		s := make([]interface{}, 0, len(n.List))
+		for _, e := range n.List {
+			v, err := convert(e)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		return s, nil
+	case *ast.LiteralType:
+		return n.Token.Value(), nil
+	default:
+		return nil, posError(n.Pos(), "unsupported node %T", n)
+	}
+}
+
+func keyOf(k *ast.ObjectKey) string {
+	if s, ok := k.Token.Value().(string); ok {
+		return s
+	}
+
+	return k.Token.Text
+}
+
+func posError(pos token.Pos, format string, v ...interface{}) error {
+	return parser.NewParseError(pos.Line, pos.Column, fmt.Errorf(format, v...))
+}
+
+func init() {
+	parser.Register(NewBuilder())
+}
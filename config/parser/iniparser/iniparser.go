@@ -0,0 +1,146 @@
+package iniparser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/k8s-practice/octopus/internal/configsearch"
+)
+
+const (
+	format = "ini"
+
+	// delim separates the nested section names and keys.
+	delim = "."
+)
+
+func Format() string {
+	return format
+}
+
+func IsMatchFormat(fmt string) bool {
+	return fmt == format
+}
+
+// NewBuilder creates the builder of the INI parser.
+func NewBuilder() parser.Builder {
+	return &builder{}
+}
+
+type builder struct{}
+
+func (b *builder) Format() []string {
+	return []string{format}
+}
+
+func (b *builder) Build() parser.Parser {
+	return &iniParser{}
+}
+
+type iniParser struct{}
+
+// Parse parses INI data. The keys before the first section are top level
+// keys. The dotted section names and keys become nested paths, e.g. key
+// "info.addr" in section [database] is "database.info.addr".
+// Lines starting with ';' or '#' are comments, values could be quoted.
+// All values are strings.
+func (p *iniParser) Parse(data []byte, v interface{}) error {
+	m := make(map[string]interface{})
+	var section []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == ';' || line[0] == '#':
+			continue
+		case line[0] == '[':
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return parser.NewParseError(lineno, 0, fmt.Errorf("unterminated section %q", line))
+			}
+			name := strings.TrimSpace(line[1:end])
+			if name == "" {
+				return parser.NewParseError(lineno, 0, fmt.Errorf("empty section name"))
+			}
+			section = strings.Split(name, delim)
+			for i := range section {
+				section[i] = strings.TrimSpace(section[i])
+			}
+			continue
+		}
+
+		sep := strings.IndexAny(line, "=:")
+		if sep <= 0 {
+			return parser.NewParseError(lineno, 0, fmt.Errorf("expected key = value, got %q", line))
+		}
+		key := strings.TrimSpace(line[:sep])
+		value, err := unquote(strings.TrimSpace(line[sep+1:]))
+		if err != nil {
+			return parser.NewParseError(lineno, 0, err)
+		}
+
+		path := append(append([]string{}, section...), strings.Split(key, delim)...)
+		if err = configsearch.InsertValueInMap(m, path, value); err != nil {
+			return parser.NewParseError(lineno, 0, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return parser.Assign(m, v)
+}
+
+// unquote unquotes the quoted value, or strips the inline comment of the
+// unquoted value.
+func unquote(s string) (string, error) {
+	if s == "" {
+		return s, nil
+	}
+
+	switch s[0] {
+	case '"':
+		end := closingQuote(s)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", s)
+		}
+		return strconv.Unquote(s[:end+1])
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value %s", s)
+		}
+		return s[1 : end+1], nil
+	}
+
+	for i := 1; i < len(s); i++ {
+		if (s[i] == ';' || s[i] == '#') && (s[i-1] == ' ' || s[i-1] == '\t') {
+			return strings.TrimSpace(s[:i]), nil
+		}
+	}
+
+	return s, nil
+}
+
+// closingQuote returns the index of the closing double quote of s.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+
+	return -1
+}
+
+func init() {
+	parser.Register(NewBuilder())
+}
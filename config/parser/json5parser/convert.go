@@ -0,0 +1,319 @@
+package json5parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/k8s-practice/octopus/config/parser"
+)
+
+// converter converts JSON5 into strict JSON.
+type converter struct {
+	src []byte
+	out []byte
+	// pos maps the position in out to the position in src.
+	pos []int
+	// i is the position in src.
+	i int
+}
+
+func newConverter(src []byte) *converter {
+	n := len(src) + len(src)/8
+	return &converter{src: src, out: make([]byte, 0, n), pos: make([]int, 0, n)}
+}
+
+func (c *converter) convert() ([]byte, error) {
+	for c.i < len(c.src) {
+		if err := c.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.out, nil
+}
+
+// emit writes b into out, which is converted from src at c.i.
+func (c *converter) emit(b ...byte) {
+	c.emitAt(c.i, string(b))
+}
+
+// emitAt writes s into out, which is converted from src at offset.
+func (c *converter) emitAt(offset int, s string) {
+	c.out = append(c.out, s...)
+	for i := 0; i < len(s); i++ {
+		c.pos = append(c.pos, offset)
+	}
+}
+
+// source returns the position in src of the position offset in out.
+func (c *converter) source(offset int) int {
+	switch {
+	case offset < 0:
+		return 0
+	case offset < len(c.pos):
+		return c.pos[offset]
+	default:
+		return len(c.src)
+	}
+}
+
+// next converts the token at c.i.
+func (c *converter) next() error {
+	b := c.src[c.i]
+	switch {
+	case b == '"' || b == '\'':
+		return c.str(b)
+	case b == '/' && c.peek(1) == '/':
+		c.lineComment()
+	case b == '/' && c.peek(1) == '*':
+		return c.blockComment()
+	case b == ',':
+		c.comma()
+	case b == '+' || b == '-' || b == '.' || isDigit(b):
+		return c.number()
+	case isIdentStart(b):
+		return c.ident()
+	default:
+		c.emit(b)
+		c.i++
+	}
+
+	return nil
+}
+
+func (c *converter) peek(n int) byte {
+	if c.i+n < len(c.src) {
+		return c.src[c.i+n]
+	}
+
+	return 0
+}
+
+// errorf reports error at c.i.
+func (c *converter) errorf(format string, v ...interface{}) error {
+	return c.errorAt(c.i, format, v...)
+}
+
+// errorAt reports error at offset of src.
+func (c *converter) errorAt(offset int, format string, v ...interface{}) error {
+	return parser.NewOffsetError(c.src, offset, fmt.Errorf(format, v...))
+}
+
+// str converts a string quoted by quote into a double quoted string.
+func (c *converter) str(quote byte) error {
+	c.emit('"')
+	for c.i++; c.i < len(c.src); c.i++ {
+		b := c.src[c.i]
+		switch {
+		case b == quote:
+			c.emit('"')
+			c.i++
+			return nil
+		case b == '\\':
+			c.i++
+			if err := c.escape(); err != nil {
+				return err
+			}
+		case b == '"':
+			c.emit('\\', '"')
+		case b == '\n':
+			return c.errorf("unterminated string")
+		default:
+			c.emit(b)
+		}
+	}
+
+	return c.errorf("unterminated string")
+}
+
+// escape converts the escape sequence at c.i, after the backslash, into
+// JSON. The escapes not in JSON are translated into \u00XX, and the other
+// characters escape to themselves.
+func (c *converter) escape() error {
+	start := c.i - 1
+	switch n := c.peek(0); {
+	case n == '\n':
+		// Line continuation.
+	case n == '\r':
+		if c.peek(1) == '\n' {
+			c.i++
+		}
+	case strings.IndexByte(`"\/bfnrtu`, n) >= 0:
+		c.emit('\\', n)
+	case n == 'v':
+		c.emitAt(start, `\u000b`)
+	case n == '0' && !isDigit(c.peek(1)):
+		c.emitAt(start, `\u0000`)
+	case n == 'x':
+		if !isHexDigit(c.peek(1)) || !isHexDigit(c.peek(2)) {
+			return c.errorAt(start, "invalid hexadecimal escape")
+		}
+		c.emitAt(start, `\u00`+string(c.src[c.i+1:c.i+3]))
+		c.i += 2
+	case isDigit(n):
+		return c.errorAt(start, "invalid escape \\%c", n)
+	default:
+		c.emit(n)
+	}
+
+	return nil
+}
+
+// lineComment replaces the comment with spaces.
+func (c *converter) lineComment() {
+	for c.i < len(c.src) && c.src[c.i] != '\n' {
+		c.emit(' ')
+		c.i++
+	}
+}
+
+// blockComment replaces the comment with spaces, and keeps newlines.
+func (c *converter) blockComment() error {
+	end := strings.Index(string(c.src[c.i+2:]), "*/")
+	if end < 0 {
+		return c.errorf("unterminated comment")
+	}
+
+	for stop := c.i + 2 + end + 2; c.i < stop; c.i++ {
+		if c.src[c.i] == '\n' {
+			c.emit('\n')
+		} else {
+			c.emit(' ')
+		}
+	}
+
+	return nil
+}
+
+// comma drops the trailing comma.
+func (c *converter) comma() {
+	c.i++
+	if j := c.skip(c.i); j < len(c.src) && (c.src[j] == '}' || c.src[j] == ']') {
+		c.emit(' ')
+		return
+	}
+
+	c.emit(',')
+}
+
+// skip returns the position of the first byte after j which is neither
+// whitespace nor in comments.
+func (c *converter) skip(j int) int {
+	for j < len(c.src) {
+		switch b := c.src[j]; {
+		case b == ' ' || b == '\t' || b == '\r' || b == '\n':
+			j++
+		case b == '/' && j+1 < len(c.src) && c.src[j+1] == '/':
+			for j < len(c.src) && c.src[j] != '\n' {
+				j++
+			}
+		case b == '/' && j+1 < len(c.src) && c.src[j+1] == '*':
+			end := strings.Index(string(c.src[j+2:]), "*/")
+			if end < 0 {
+				return len(c.src)
+			}
+			j += 2 + end + 2
+		default:
+			return j
+		}
+	}
+
+	return j
+}
+
+// number converts JSON5 number into JSON number.
+func (c *converter) number() error {
+	start := c.i
+	for c.i < len(c.src) {
+		b := c.src[c.i]
+		prev := byte(0)
+		if c.i > start {
+			prev = c.src[c.i-1]
+		}
+		isSign := (b == '+' || b == '-') &&
+			(c.i == start || ((prev == 'e' || prev == 'E') && !isHex(string(c.src[start:c.i]))))
+		if !isSign && b != '.' && !isDigit(b) && !isIdentPart(b) {
+			break
+		}
+		c.i++
+	}
+
+	tok := string(c.src[start:c.i])
+	sign := ""
+	if tok[0] == '+' || tok[0] == '-' {
+		sign, tok = tok[:1], tok[1:]
+		if sign == "+" {
+			sign = ""
+		}
+	}
+
+	switch {
+	case tok == "Infinity" || tok == "NaN":
+		return c.errorAt(start, "%s%s is not supported", sign, tok)
+	case isHex(tok):
+		v, err := strconv.ParseUint(tok[2:], 16, 64)
+		if err != nil {
+			return c.errorAt(start, "invalid number %s", tok)
+		}
+		tok = strconv.FormatUint(v, 10)
+	default:
+		if strings.HasPrefix(tok, ".") {
+			tok = "0" + tok
+		}
+		if strings.HasSuffix(tok, ".") {
+			tok = tok + "0"
+		}
+		tok = strings.Replace(tok, ".e", ".0e", 1)
+		tok = strings.Replace(tok, ".E", ".0E", 1)
+	}
+
+	c.emitAt(start, sign+tok)
+
+	return nil
+}
+
+// ident converts identifier, the unquoted key is quoted.
+// Identifiers other than keys and literals are not allowed.
+func (c *converter) ident() error {
+	start := c.i
+	for c.i < len(c.src) && isIdentPart(c.src[c.i]) {
+		c.i++
+	}
+	tok := string(c.src[start:c.i])
+
+	switch tok {
+	case "true", "false", "null":
+		c.emitAt(start, tok)
+		return nil
+	case "Infinity", "NaN":
+		return c.errorAt(start, "%s is not supported", tok)
+	}
+
+	if j := c.skip(c.i); j >= len(c.src) || c.src[j] != ':' {
+		return c.errorAt(start, "unexpected identifier %s", tok)
+	}
+	c.emitAt(start, `"`+tok+`"`)
+
+	return nil
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isHex(s string) bool {
+	return len(s) > 1 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X')
+}
+
+func isHexDigit(b byte) bool {
+	return isDigit(b) || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
+
+func isIdentStart(b byte) bool {
+	return b == '_' || b == '$' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func isIdentPart(b byte) bool {
+	return isIdentStart(b) || isDigit(b)
+}
@@ -0,0 +1,80 @@
+package json5parser
+
+import (
+	"encoding/json"
+
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/k8s-practice/octopus/config/parser/jsonparser"
+)
+
+const (
+	format       = "json5"
+	format_alias = "jsonc"
+)
+
+func Format() string {
+	return format
+}
+
+func IsMatchFormat(fmt string) bool {
+	return fmt == format || fmt == format_alias
+}
+
+// Option configures the parsers built by the builder.
+type Option = jsonparser.Option
+
+// WithUseNumber decodes numbers as json.Number rather than float64,
+// it avoids precision loss of integers greater than 2^53.
+func WithUseNumber() Option {
+	return jsonparser.WithUseNumber()
+}
+
+// NewBuilder creates a builder with opts, registering it replaces the
+// default one, e.g.
+//
+//	parser.Register(json5parser.NewBuilder(json5parser.WithUseNumber()))
+func NewBuilder(opts ...Option) parser.Builder {
+	return &builder{jsonparser.NewOptions(opts...)}
+}
+
+type builder struct {
+	jsonparser.Options
+}
+
+func (b *builder) Format() []string {
+	return []string{format, format_alias}
+}
+
+func (b *builder) Build() parser.Parser {
+	return &json5Parser{b.Options}
+}
+
+// json5Parser parses JSON with comments, trailing commas, unquoted keys,
+// single quoted strings, hexadecimal numbers, and numbers with leading '+'
+// or leading or trailing decimal point. Infinity and NaN are not supported.
+type json5Parser struct {
+	jsonparser.Options
+}
+
+// Parse converts data into JSON, and decodes it by jsonparser. The errors
+// are reported at the positions of data.
+func (p *json5Parser) Parse(data []byte, v interface{}) error {
+	c := newConverter(data)
+	out, err := c.convert()
+	if err != nil {
+		return err
+	}
+
+	return p.Decode(out, v, func(offset int, err error) error {
+		return parser.NewOffsetError(c.src, c.source(offset), err)
+	})
+}
+
+// Marshal encodes v as strict JSON, which is valid JSON5.
+func (p *json5Parser) Marshal(v interface{}) ([]byte, error) {
+	return json.MarshalIndent(v, "", "    ")
+}
+
+func init() {
+	parser.Register(NewBuilder())
+}
@@ -1,7 +1,10 @@
 package jsonparser
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 
 	"github.com/k8s-practice/octopus/config/parser"
 )
@@ -18,22 +21,92 @@ func IsMatchFormat(fmt string) bool {
 	return fmt == format
 }
 
-type builder struct{}
+// Options configures the JSON parsers, it's shared by the parsers of the
+// dialects converted into JSON, e.g. json5parser.
+type Options struct {
+	// UseNumber decodes numbers as json.Number rather than float64.
+	UseNumber bool
+}
+
+// Option configures the parsers built by the builder.
+type Option func(o *Options)
+
+// WithUseNumber decodes numbers as json.Number rather than float64,
+// it avoids precision loss of integers greater than 2^53.
+func WithUseNumber() Option {
+	return func(o *Options) {
+		o.UseNumber = true
+	}
+}
+
+// NewOptions creates Options with opts.
+func NewOptions(opts ...Option) Options {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// NewBuilder creates a builder with opts, registering it replaces the
+// default one, e.g.
+//
+//	parser.Register(jsonparser.NewBuilder(jsonparser.WithUseNumber()))
+func NewBuilder(opts ...Option) parser.Builder {
+	return &builder{NewOptions(opts...)}
+}
+
+type builder struct {
+	Options
+}
 
 func (b *builder) Build() parser.Parser {
-	return &jsonParser{}
+	return &jsonParser{b.Options}
 }
 
 func (b *builder) Format() []string {
 	return []string{format}
 }
 
-type jsonParser struct{}
+type jsonParser struct {
+	Options
+}
 
 func (p *jsonParser) Parse(data []byte, v interface{}) error {
-	return json.Unmarshal(data, v)
+	return p.Decode(data, v, func(offset int, err error) error {
+		return parser.NewOffsetError(data, offset, err)
+	})
+}
+
+// Decode decodes the single JSON value in data into v. The errors with
+// offset in data are converted by errorAt, e.g. into parser.ParseError.
+func (o Options) Decode(data []byte, v interface{}, errorAt func(offset int, err error) error) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if o.UseNumber {
+		dec.UseNumber()
+	}
+
+	if err := dec.Decode(v); err == io.EOF {
+		return errorAt(len(data), errors.New("unexpected end of JSON input"))
+	} else if e, ok := err.(*json.SyntaxError); ok {
+		return errorAt(int(e.Offset)-1, err)
+	} else if e, ok := err.(*json.UnmarshalTypeError); ok {
+		return errorAt(int(e.Offset)-1, err)
+	} else if err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errorAt(int(dec.InputOffset()), errors.New("invalid character after top-level value"))
+	}
+
+	return nil
+}
+
+func (p *jsonParser) Marshal(v interface{}) ([]byte, error) {
+	return json.MarshalIndent(v, "", "    ")
 }
 
 func init() {
-	parser.Register(&builder{})
+	parser.Register(NewBuilder())
 }
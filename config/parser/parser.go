@@ -1,14 +1,28 @@
+// Package parser parses and encodes the data of datasources by formats.
+//
+// The parser packages, e.g. yamlparser, register their builders into
+// DefaultRegistry while imported. They also export NewBuilder, so that the
+// builders could be registered into a Registry explicitly, e.g.
+//
+//	r := parser.NewRegistry()
+//	r.Register(yamlparser.NewBuilder())
+//	c, err := config.New(t, config.WithParsers(r))
 package parser
 
 import (
 	"fmt"
-	"log"
+	"sort"
 	"strings"
+	"sync"
+
+	"github.com/k8s-practice/octopus/xlog"
+	"github.com/mitchellh/mapstructure"
 )
 
 var (
-	// parsers is a map from format to config parser builder.
-	parsers = make(map[string]Builder)
+	// DefaultRegistry is the registry used by the package-level functions,
+	// the builtin parsers register themselves into it.
+	DefaultRegistry = NewRegistry()
 )
 
 // Parser parses configurations from input data.
@@ -16,6 +30,12 @@ type Parser interface {
 	Parse(data []byte, v interface{}) error
 }
 
+// Encoder encodes configurations into data.
+// It's optional for Parser, the Parser implements it supports Marshal.
+type Encoder interface {
+	Marshal(v interface{}) ([]byte, error)
+}
+
 // Builder builds a config parser to parse configiration.
 type Builder interface {
 	Build() Parser
@@ -24,21 +44,124 @@ type Builder interface {
 	Format() []string
 }
 
-func Register(b Builder) {
-	log.Printf("Register paser [%s]\n", b.Format())
+// Registry is a concurrent-safe map from format to config parser builder.
+type Registry struct {
+	mu      sync.RWMutex
+	parsers map[string]Builder
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{parsers: make(map[string]Builder)}
+}
+
+// Register registers the parser builder for all its formats, the builders
+// registered before for the same formats are replaced.
+func (r *Registry) Register(b Builder) {
+	xlog.Infof("Register paser %v.", b.Format())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	for _, format := range b.Format() {
-		parsers[strings.ToLower(format)] = b
+		r.parsers[strings.ToLower(format)] = b
+	}
+}
+
+// Unregister removes the parser builder of format.
+func (r *Registry) Unregister(format string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.parsers, strings.ToLower(format))
+}
+
+// Lookup returns the parser builder of format.
+func (r *Registry) Lookup(format string) (Builder, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	b, ok := r.parsers[strings.ToLower(format)]
+
+	return b, ok
+}
+
+// Formats returns all registered formats in order.
+func (r *Registry) Formats() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	formats := make([]string, 0, len(r.parsers))
+	for format := range r.parsers {
+		formats = append(formats, format)
 	}
+	sort.Strings(formats)
+
+	return formats
 }
 
 // Parse uses registered parser to parse the coming data.
 // - format is used to search parser.
 // - data is the data need to parse.
 // - v is output parameter.
-func Parse(format string, data []byte, v interface{}) error {
-	if builder, ok := parsers[strings.ToLower(format)]; ok {
-		return builder.Build().Parse(data, v)
+// The returned error is a *ParseError if the data fails to parse.
+func (r *Registry) Parse(format string, data []byte, v interface{}) error {
+	if builder, ok := r.Lookup(format); ok {
+		if err := builder.Build().Parse(data, v); err != nil {
+			return asParseError(format, data, err)
+		}
+		return nil
 	}
 
 	return fmt.Errorf("Unsupported parse format [%s].", format)
 }
+
+// Marshal uses registered parser to encode v into data of format.
+func (r *Registry) Marshal(format string, v interface{}) ([]byte, error) {
+	if builder, ok := r.Lookup(format); ok {
+		if e, ok := builder.Build().(Encoder); ok {
+			return e.Marshal(v)
+		}
+	}
+
+	return nil, fmt.Errorf("Unsupported encode format [%s].", format)
+}
+
+// Register registers the parser builder into DefaultRegistry.
+func Register(b Builder) {
+	DefaultRegistry.Register(b)
+}
+
+// Unregister removes the parser builder of format from DefaultRegistry.
+func Unregister(format string) {
+	DefaultRegistry.Unregister(format)
+}
+
+// Formats returns all formats registered in DefaultRegistry in order.
+func Formats() []string {
+	return DefaultRegistry.Formats()
+}
+
+// Parse parses data by the parser registered in DefaultRegistry.
+func Parse(format string, data []byte, v interface{}) error {
+	return DefaultRegistry.Parse(format, data, v)
+}
+
+// Marshal encodes v by the parser registered in DefaultRegistry.
+func Marshal(format string, v interface{}) ([]byte, error) {
+	return DefaultRegistry.Marshal(format, v)
+}
+
+// Assign stores the configurations m into v, it helps the parsers which
+// build map[string]interface{} by themselves. v is usually a pointer to
+// map[string]interface{}, other types are decoded by mapstructure.
+func Assign(m map[string]interface{}, v interface{}) error {
+	if out, ok := v.(*map[string]interface{}); ok {
+		if *out == nil {
+			*out = m
+			return nil
+		}
+		for k, x := range m {
+			(*out)[k] = x
+		}
+		return nil
+	}
+
+	return mapstructure.Decode(m, v)
+}
@@ -0,0 +1,165 @@
+package propertiesparser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/k8s-practice/octopus/internal/configsearch"
+)
+
+const (
+	format       = "properties"
+	format_alias = "props"
+
+	// delim separates the nested keys.
+	delim = "."
+)
+
+func Format() string {
+	return format
+}
+
+func IsMatchFormat(fmt string) bool {
+	return fmt == format || fmt == format_alias
+}
+
+// NewBuilder creates the builder of the properties parser.
+func NewBuilder() parser.Builder {
+	return &builder{}
+}
+
+type builder struct{}
+
+func (b *builder) Format() []string {
+	return []string{format, format_alias}
+}
+
+func (b *builder) Build() parser.Parser {
+	return &propertiesParser{}
+}
+
+type propertiesParser struct{}
+
+// Parse parses Java properties data. Keys and values are separated by '=',
+// ':' or whitespace, lines starting with '#' or '!' are comments, a line
+// ending with an odd number of backslashes continues on the next line.
+// Escapes like "\t", "\n" and "\uXXXX" are supported. Dotted keys become
+// nested paths. All values are strings.
+func (p *propertiesParser) Parse(data []byte, v interface{}) error {
+	m := make(map[string]interface{})
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		// Joins continuation lines.
+		start := lineno
+		for continued(line) && scanner.Scan() {
+			lineno++
+			line = line[:len(line)-1] + strings.TrimLeft(scanner.Text(), " \t\f")
+		}
+
+		key, value, err := split(line)
+		if err != nil {
+			return parser.NewParseError(start, 0, err)
+		}
+		if err = configsearch.InsertValueInMap(m, strings.Split(key, delim), value); err != nil {
+			return parser.NewParseError(start, 0, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return parser.Assign(m, v)
+}
+
+// continued reports whether line ends with an odd number of backslashes.
+func continued(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+
+	return n%2 == 1
+}
+
+// split splits line into unescaped key and value.
+func split(line string) (string, string, error) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+
+	key, err := unescape(line[:end])
+	if err != nil {
+		return "", "", err
+	}
+
+	// The separator is whitespaces optionally followed by '=' or ':'.
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	value, err := unescape(rest)
+
+	return key, value, err
+}
+
+func unescape(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 >= len(s) {
+				return "", fmt.Errorf("invalid unicode escape %q", s[i-1:])
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape %q", s[i-1:i+5])
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+func init() {
+	parser.Register(NewBuilder())
+}
@@ -1,6 +1,11 @@
 package tomlparser
 
 import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+
 	"github.com/BurntSushi/toml"
 	"github.com/k8s-practice/octopus/config/parser"
 )
@@ -9,6 +14,9 @@ const (
 	format = "toml"
 )
 
+// lineError matches the message of toml parse errors.
+var lineError = regexp.MustCompile(`^Near line (\d+) \(last key parsed '.*?'\): (?s:(.*))$`)
+
 func Format() string {
 	return format
 }
@@ -17,6 +25,11 @@ func IsMatchFormat(fmt string) bool {
 	return fmt == format
 }
 
+// NewBuilder creates the builder of the TOML parser.
+func NewBuilder() parser.Builder {
+	return &builder{}
+}
+
 type builder struct{}
 
 func (b *builder) Format() []string {
@@ -30,9 +43,32 @@ func (b *builder) Build() parser.Parser {
 type tomlParser struct{}
 
 func (p *tomlParser) Parse(data []byte, v interface{}) error {
-	return toml.Unmarshal(data, v)
+	return positionError(toml.Unmarshal(data, v))
+}
+
+// positionError converts the error with line number into parser.ParseError.
+func positionError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if m := lineError.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return parser.NewParseError(line, 0, errors.New(m[2]))
+	}
+
+	return err
+}
+
+func (p *tomlParser) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
 }
 
 func init() {
-	parser.Register(&builder{})
+	parser.Register(NewBuilder())
 }
@@ -1,15 +1,29 @@
 package yamlparser
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
 	"github.com/k8s-practice/octopus/config/parser"
-	"gopkg.in/yaml.v2"
+	"github.com/k8s-practice/octopus/internal/configsearch"
+	"gopkg.in/yaml.v3"
 )
 
 const (
 	format       = "yaml"
 	format_alias = "yml"
+
+	// mergeTag is the tag of merge key "<<".
+	mergeTag = "!!merge"
 )
 
+// lineError matches the message of yaml syntax errors.
+var lineError = regexp.MustCompile(`^yaml: line (\d+): (?s:(.*))$`)
+
 func Format() string {
 	return format
 }
@@ -18,6 +32,11 @@ func IsMatchFormat(fmt string) bool {
 	return fmt == format || fmt == format_alias
 }
 
+// NewBuilder creates the builder of the YAML parser.
+func NewBuilder() parser.Builder {
+	return &builder{}
+}
+
 type builder struct{}
 
 func (b *builder) Format() []string {
@@ -30,10 +49,190 @@ func (b *builder) Build() parser.Parser {
 
 type yamlParser struct{}
 
+// Parse parses all documents in data, the later documents are deeply merged
+// into the former ones. Nested maps are normalized to map[string]interface{},
+// anchors, aliases and merge keys are resolved.
 func (p *yamlParser) Parse(data []byte, v interface{}) error {
-	return yaml.Unmarshal(data, v)
+	var merged interface{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return positionError(err)
+		}
+
+		value, err := convert(&doc)
+		if err != nil {
+			return err
+		}
+		merged = configsearch.Merge(merged, value)
+	}
+
+	return assign(merged, v)
+}
+
+func (p *yamlParser) Marshal(v interface{}) ([]byte, error) {
+	return yaml.Marshal(v)
+}
+
+// nodeError reports error at the position of n.
+func nodeError(n *yaml.Node, format string, v ...interface{}) error {
+	return parser.NewParseError(n.Line, n.Column, fmt.Errorf(format, v...))
+}
+
+// positionError converts the error with line number into parser.ParseError.
+func positionError(err error) error {
+	if m := lineError.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return parser.NewParseError(line, 0, errors.New(m[2]))
+	}
+
+	return err
+}
+
+// convert converts n into normalized value.
+func convert(n *yaml.Node) (interface{}, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return convert(n.Content[0])
+	case yaml.AliasNode:
+		return convert(n.Alias)
+	case yaml.SequenceNode:
+		s := make([]interface{}, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := convert(c)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		return s, nil
+	case yaml.MappingNode:
+		return convertMapping(n)
+	default:
+		var v interface{}
+		if err := n.Decode(&v); err != nil {
+			return nil, nodeError(n, "%v", err)
+		}
+		return v, nil
+	}
+}
+
+// convertMapping converts mapping node n into map[string]interface{}.
+// The explicit keys take precedence over the merged ones, and the former
+// merged mappings take precedence over the later ones.
+func convertMapping(n *yaml.Node) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, len(n.Content)/2)
+	keys := make(map[string]*yaml.Node, len(n.Content)/2)
+	var merges []map[string]interface{}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if k.Kind == yaml.AliasNode {
+			k = k.Alias
+		}
+		if k.Kind != yaml.ScalarNode {
+			return nil, nodeError(k, "mapping key must be a scalar")
+		}
+
+		if k.ShortTag() == mergeTag {
+			ms, err := convertMerge(v)
+			if err != nil {
+				return nil, err
+			}
+			merges = append(merges, ms...)
+			continue
+		}
+
+		if prev, ok := keys[k.Value]; ok {
+			return nil, nodeError(k, "mapping key %q already defined at line %d, column %d",
+				k.Value, prev.Line, prev.Column)
+		}
+		keys[k.Value] = k
+
+		value, err := convert(v)
+		if err != nil {
+			return nil, err
+		}
+		m[k.Value] = value
+	}
+
+	for _, merged := range merges {
+		for k, v := range merged {
+			if _, ok := m[k]; !ok {
+				m[k] = v
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// convertMerge converts the value of merge key, which must be a mapping or
+// a sequence of mappings.
+func convertMerge(n *yaml.Node) ([]map[string]interface{}, error) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		m, err := convertMapping(n)
+		if err != nil {
+			return nil, err
+		}
+		return []map[string]interface{}{m}, nil
+	case yaml.SequenceNode:
+		ms := make([]map[string]interface{}, 0, len(n.Content))
+		for _, c := range n.Content {
+			if c.Kind == yaml.AliasNode {
+				c = c.Alias
+			}
+			if c.Kind != yaml.MappingNode {
+				return nil, nodeError(c, "merge value must be a mapping")
+			}
+			m, err := convertMapping(c)
+			if err != nil {
+				return nil, err
+			}
+			ms = append(ms, m)
+		}
+		return ms, nil
+	default:
+		return nil, nodeError(n, "merge value must be a mapping or a sequence of mappings")
+	}
+}
+
+// assign stores value into v.
+func assign(value interface{}, v interface{}) error {
+	switch out := v.(type) {
+	case *map[string]interface{}:
+		if value == nil {
+			return nil
+		}
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("yaml: cannot unmarshal %T into map[string]interface{}", value)
+		}
+		return parser.Assign(m, out)
+	case *interface{}:
+		*out = value
+		return nil
+	default:
+		// Decode the normalized value by yaml to respect the yaml tags of v.
+		data, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		return yaml.Unmarshal(data, v)
+	}
 }
 
 func init() {
-	parser.Register(&builder{})
+	parser.Register(NewBuilder())
 }
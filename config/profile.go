@@ -0,0 +1,89 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/k8s-practice/octopus/config/datasource"
+)
+
+const (
+	// ENV_PROFILE is the environment variable of the active profile.
+	ENV_PROFILE = "OCTOPUS_PROFILE"
+
+	// FLAG_PROFILE is the command-line flag of the active profile, it takes
+	// precedence over ENV_PROFILE. The flag is defined by the application.
+	FLAG_PROFILE = "profile"
+
+	// LOCAL_PROFILE is the profile of the local overrides, which is always
+	// layered on the top.
+	LOCAL_PROFILE = "local"
+)
+
+// ActiveProfile returns the value of flag FLAG_PROFILE if it's defined and
+// not empty, otherwise the value of environment variable ENV_PROFILE.
+func ActiveProfile() string {
+	if f := flag.Lookup(FLAG_PROFILE); f != nil && f.Value.String() != "" {
+		return f.Value.String()
+	}
+
+	return os.Getenv(ENV_PROFILE)
+}
+
+// NewProfile creates a MultiConfig layering the profiles of the base target
+// t, e.g. the path of t is "conf/app.yaml" and profile is "prod":
+//
+//	conf/app.local.yaml  optional, the highest priority
+//	conf/app.prod.yaml   optional, skipped if profile is empty
+//	conf/app.yaml        required, the lowest priority
+//
+// opts apply to every layer. The targets of the profiles copy the values of
+// t, only the scheme, format and path are copied if t is not created by T.
+// The Schema given by WithSchema validates every layer as WithLayerSchema,
+// and validates the combined configurations.
+func NewProfile(t datasource.Target, profile string, opts ...Option) (Config, error) {
+	profiles := []string{LOCAL_PROFILE}
+	if profile != "" && profile != LOCAL_PROFILE {
+		profiles = append(profiles, profile)
+	}
+
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.schema != nil {
+		opts = append(append([]Option{}, opts...), WithLayerSchema(o.schema))
+	}
+
+	layers := make([]Config, 0, len(profiles)+1)
+	optional := append(append([]Option{}, opts...), WithOptional())
+	for _, p := range profiles {
+		c, err := New(cloneTarget(t).WithPath(ProfilePath(t.Path(), p)), optional...)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, c)
+	}
+
+	c, err := New(t, opts...)
+	if err != nil {
+		return nil, err
+	}
+	layers = append(layers, c)
+
+	mc := &multiConfig{layers}
+	if err = mc.validate(mc); err != nil {
+		return nil, err
+	}
+
+	return mc, nil
+}
+
+// ProfilePath inserts profile before the extension of path, e.g.
+// "conf/app.yaml" becomes "conf/app.prod.yaml".
+func ProfilePath(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
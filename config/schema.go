@@ -0,0 +1,293 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/k8s-practice/octopus/internal/configsearch"
+	"github.com/k8s-practice/octopus/utils/cast"
+)
+
+// Kind is the expected kind of a configuration value.
+type Kind int
+
+const (
+	KindAny Kind = iota
+	KindBool
+	KindInt
+	KindUint
+	KindFloat
+	KindString
+	KindDuration
+	KindTime
+	KindSlice
+	KindMap
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindAny:
+		return "any"
+	case KindBool:
+		return "bool"
+	case KindInt:
+		return "int"
+	case KindUint:
+		return "uint"
+	case KindFloat:
+		return "float"
+	case KindString:
+		return "string"
+	case KindDuration:
+		return "duration"
+	case KindTime:
+		return "time"
+	case KindSlice:
+		return "slice"
+	case KindMap:
+		return "map"
+	default:
+		return fmt.Sprintf("Kind(%d)", k)
+	}
+}
+
+// check returns error if v can't be used as kind k.
+func (k Kind) check(v interface{}) error {
+	var err error
+	switch k {
+	case KindBool:
+		_, err = cast.ToBoolE(v)
+	case KindInt:
+		_, err = cast.ToInt64E(v)
+	case KindUint:
+		_, err = cast.ToUint64E(v)
+	case KindFloat:
+		_, err = cast.ToFloat64E(v)
+	case KindString:
+		_, err = cast.ToStringE(v)
+	case KindDuration:
+		_, err = cast.ToDurationE(v)
+	case KindTime:
+		_, err = cast.ToTimeE(v)
+	case KindSlice:
+		if rk := reflect.TypeOf(v).Kind(); rk != reflect.Slice && rk != reflect.Array {
+			err = fmt.Errorf("%T is not a slice", v)
+		}
+	case KindMap:
+		if reflect.TypeOf(v).Kind() != reflect.Map {
+			err = fmt.Errorf("%T is not a map", v)
+		}
+	}
+
+	return err
+}
+
+// Field describes a key of the Schema.
+type Field struct {
+	Kind     Kind
+	Required bool
+}
+
+// Schema describes the keys of configurations.
+// Keys are nested with DEFAULT_KEY_DELIMITER, e.g. "database.port".
+type Schema struct {
+	fields map[string]Field
+	// strict rejects the keys which are not declared.
+	strict bool
+}
+
+// NewSchema creates an empty Schema.
+func NewSchema() *Schema {
+	return &Schema{fields: make(map[string]Field)}
+}
+
+// Key declares key with kind.
+func (s *Schema) Key(key string, kind Kind, required bool) *Schema {
+	s.fields[key] = Field{Kind: kind, Required: required}
+	return s
+}
+
+// Strict makes the keys not declared in s fail to validate,
+// it helps to find typos in configuration files.
+func (s *Schema) Strict() *Schema {
+	s.strict = true
+	return s
+}
+
+// SchemaOf describes the struct v as a Schema.
+// The key of field is the name in mapstructure tag, or the lower case of the
+// field name. Fields tagged with `schema:"required"` are required. Nested
+// structs are declared recursively, embedded structs are squashed.
+func SchemaOf(v interface{}) *Schema {
+	s := NewSchema()
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == reflect.Struct {
+		s.declareStruct("", t)
+	}
+
+	return s
+}
+
+func (s *Schema) declareStruct(prefix string, t reflect.Type) {
+	for _, f := range configsearch.Fields(t) {
+		ft := f.Type
+		required := f.Tag.Get("schema") == "required"
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		key := f.Key
+		if prefix != "" {
+			key = prefix + DEFAULT_KEY_DELIMITER + f.Key
+		}
+
+		switch {
+		case ft == configsearch.DurationType:
+			s.Key(key, KindDuration, required)
+		case ft == configsearch.TimeType:
+			s.Key(key, KindTime, required)
+		case ft.Kind() == reflect.Struct && f.Squash:
+			s.declareStruct(prefix, ft)
+		case ft.Kind() == reflect.Struct:
+			if required {
+				s.Key(key, KindMap, required)
+			}
+			s.declareStruct(key, ft)
+		default:
+			s.Key(key, kindOf(ft), required)
+		}
+	}
+}
+
+func kindOf(t reflect.Type) Kind {
+	switch t.Kind() {
+	case reflect.Bool:
+		return KindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return KindInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return KindUint
+	case reflect.Float32, reflect.Float64:
+		return KindFloat
+	case reflect.String:
+		return KindString
+	case reflect.Slice, reflect.Array:
+		return KindSlice
+	case reflect.Map:
+		return KindMap
+	default:
+		return KindAny
+	}
+}
+
+// SchemaError reports all violations found while validating.
+type SchemaError struct {
+	Violations []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("Config schema violations: %s.", strings.Join(e.Violations, "; "))
+}
+
+// Validate checks the merged configuration c: all required keys must be
+// present, and the present keys must match their kinds.
+func (s *Schema) Validate(c Config) error {
+	var violations []string
+	for _, key := range s.keys() {
+		f := s.fields[key]
+		v := c.Get(key)
+		if v == nil {
+			if f.Required {
+				violations = append(violations, fmt.Sprintf("[%s] is required", key))
+			}
+			continue
+		}
+		if err := f.Kind.check(v); err != nil {
+			violations = append(violations, fmt.Sprintf("[%s] must be %s: %v", key, f.Kind, err))
+		}
+	}
+
+	return newSchemaError(violations)
+}
+
+// ValidateLayer checks a layer of configurations, e.g. the data loaded by a
+// datasource. A layer holds only part of the keys, so the required keys are
+// not checked. The undeclared keys are rejected if s is strict.
+func (s *Schema) ValidateLayer(m map[string]interface{}) error {
+	var violations []string
+	s.walk("", m, &violations)
+
+	return newSchemaError(violations)
+}
+
+func (s *Schema) walk(prefix string, m map[string]interface{}, violations *[]string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := m[k]
+		key := k
+		if prefix != "" {
+			key = prefix + DEFAULT_KEY_DELIMITER + k
+		}
+
+		if f, ok := s.fields[key]; ok {
+			if v != nil {
+				if err := f.Kind.check(v); err != nil {
+					*violations = append(*violations, fmt.Sprintf("[%s] must be %s: %v", key, f.Kind, err))
+				}
+			}
+			if !s.nested(key) {
+				continue
+			}
+		} else if s.strict && !s.nested(key) {
+			*violations = append(*violations, fmt.Sprintf("[%s] is unknown", key))
+			continue
+		}
+
+		if isMap(v) {
+			s.walk(key, cast.ToStringMap(v), violations)
+		}
+	}
+}
+
+// nested reports whether any key nested in key is declared.
+func (s *Schema) nested(key string) bool {
+	for k := range s.fields {
+		if strings.HasPrefix(k, key+DEFAULT_KEY_DELIMITER) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Schema) keys() []string {
+	keys := make([]string, 0, len(s.fields))
+	for k := range s.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func isMap(v interface{}) bool {
+	return v != nil && reflect.TypeOf(v).Kind() == reflect.Map
+}
+
+func newSchemaError(violations []string) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return &SchemaError{Violations: violations}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"github.com/k8s-practice/octopus/internal/configsearch"
+)
+
+// Snapshot returns an immutable point-in-time copy of c, the layers of
+// MultiConfig are deeply merged by their priorities, so the snapshot gets
+// the same values as c at the moment. The snapshot is read-only and never
+// changes after reloading or modifying c.
+// Only the Configs created by this package are copied, the others are
+// treated as empty.
+func Snapshot(c Config) Config {
+	s := &config{ds: static(tree(c)), delim: DEFAULT_KEY_DELIMITER}
+	if first := firstConfig(c); first != nil {
+		s.delim, s.fold = first.delim, first.fold
+	}
+
+	return s
+}
+
+// Tree returns a deep copy of the whole data of c, the layers of
+// MultiConfig are deeply merged by their priorities like Snapshot.
+func Tree(c Config) map[string]interface{} {
+	return tree(c)
+}
+
+// tree returns a deep copy of the whole data of c.
+func tree(c Config) map[string]interface{} {
+	switch c := c.(type) {
+	case *config:
+		if m, ok := configsearch.Copy(c.ds.Get(nil)).(map[string]interface{}); ok {
+			return m
+		}
+	case *multiConfig:
+		m := make(map[string]interface{})
+		for i := len(c.allConfig) - 1; i >= 0; i-- {
+			configsearch.Merge(m, tree(c.allConfig[i]))
+		}
+		return m
+	case *Auditor:
+		return tree(c.c)
+	}
+
+	return make(map[string]interface{})
+}
+
+// firstConfig returns the first layer of c created by New.
+func firstConfig(c Config) *config {
+	switch c := c.(type) {
+	case *config:
+		return c
+	case *multiConfig:
+		for _, layer := range c.allConfig {
+			if first := firstConfig(layer); first != nil {
+				return first
+			}
+		}
+	case *Auditor:
+		return firstConfig(c.c)
+	}
+
+	return nil
+}
+
+// static implements the interface of datasource.DataSource, the data never
+// changes, config.Get returns the copies of the maps and slices.
+type static map[string]interface{}
+
+func (s static) Load() error {
+	return nil
+}
+
+func (s static) Get(path []string) interface{} {
+	return configsearch.SearchPathInMap(s, path)
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/k8s-practice/octopus/utils/cast"
+	"github.com/mitchellh/mapstructure"
+)
+
+// Value gets value by key and converts it to T.
+// Returns the zero value of T if the key is not present or the conversion
+// fails.
+func Value[T any](c Config, key string) T {
+	v, _ := ValueE[T](c, key)
+	return v
+}
+
+// ValueE gets value by key and converts it to T.
+// Returns the zero value of T and nil error if the key is not present.
+func ValueE[T any](c Config, key string) (T, error) {
+	return convert[T](c.Get(key))
+}
+
+// convert converts i to T, the basic types are converted by package cast,
+// other types are decoded by mapstructure.
+func convert[T any](i interface{}) (T, error) {
+	var t T
+	if i == nil {
+		return t, nil
+	}
+
+	var v interface{}
+	var err error
+	switch any(t).(type) {
+	case bool:
+		v, err = cast.ToBoolE(i)
+	case int:
+		v, err = cast.ToIntE(i)
+	case int8:
+		v, err = cast.ToInt8E(i)
+	case int16:
+		v, err = cast.ToInt16E(i)
+	case int32:
+		v, err = cast.ToInt32E(i)
+	case int64:
+		v, err = cast.ToInt64E(i)
+	case uint:
+		v, err = cast.ToUintE(i)
+	case uint8:
+		v, err = cast.ToUint8E(i)
+	case uint16:
+		v, err = cast.ToUint16E(i)
+	case uint32:
+		v, err = cast.ToUint32E(i)
+	case uint64:
+		v, err = cast.ToUint64E(i)
+	case float32:
+		v, err = cast.ToFloat32E(i)
+	case float64:
+		v, err = cast.ToFloat64E(i)
+	case string:
+		v, err = cast.ToStringE(i)
+	case time.Time:
+		v, err = cast.ToTimeE(i)
+	case time.Duration:
+		v, err = cast.ToDurationE(i)
+	case []interface{}:
+		v, err = cast.ToSliceE(i)
+	case []bool:
+		v, err = cast.ToBoolSliceE(i)
+	case []int:
+		v, err = cast.ToIntSliceE(i)
+	case []string:
+		v, err = cast.ToStringSliceE(i)
+	case []time.Duration:
+		v, err = cast.ToDurationSliceE(i)
+	case map[string]interface{}:
+		v, err = cast.ToStringMapE(i)
+	case map[string]string:
+		v, err = cast.ToStringMapStringE(i)
+	case map[string][]string:
+		v, err = cast.ToStringMapStringSliceE(i)
+	case map[string]bool:
+		v, err = cast.ToStringMapBoolE(i)
+	case map[string]int:
+		v, err = cast.ToStringMapIntE(i)
+	case map[string]int64:
+		v, err = cast.ToStringMapInt64E(i)
+	default:
+		if x, ok := i.(T); ok {
+			return x, nil
+		}
+		err = mapstructure.Decode(i, &t)
+		return t, err
+	}
+
+	if err != nil {
+		return t, err
+	}
+
+	return v.(T), nil
+}
+
+// Atomic holds a value of T, which is safe for concurrent use.
+// The value bound by Bind is refreshed after the config reloaded.
+type Atomic[T any] struct {
+	v atomic.Value
+}
+
+// box makes it possible to store nil interface in atomic.Value.
+type box[T any] struct {
+	v T
+}
+
+// Load returns the value holding by a.
+func (a *Atomic[T]) Load() T {
+	if b, ok := a.v.Load().(box[T]); ok {
+		return b.v
+	}
+
+	var t T
+	return t
+}
+
+// Store sets the value holding by a.
+func (a *Atomic[T]) Store(v T) {
+	a.v.Store(box[T]{v})
+}
+
+// Bind returns a handle holding the value of key, the value is refreshed
+// every time c reloads if c implements Watcher.
+// A reloaded value which can't be converted to T leaves the handle unchanged.
+func Bind[T any](c Config, key string) *Atomic[T] {
+	a := &Atomic[T]{}
+	a.Store(Value[T](c, key))
+
+	if w, ok := c.(Watcher); ok {
+		w.Watch(func() {
+			if v, err := ValueE[T](c, key); err == nil {
+				a.Store(v)
+			}
+		})
+	}
+
+	return a
+}
@@ -4,15 +4,13 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"path/filepath"
-	"strings"
 
 	"github.com/k8s-practice/octopus"
 	"github.com/k8s-practice/octopus/config"
 	"github.com/k8s-practice/octopus/config/datasource/localfile"
-	"github.com/k8s-practice/octopus/config/parser/jsonparser"
-	"github.com/k8s-practice/octopus/config/parser/tomlparser"
-	"github.com/k8s-practice/octopus/config/parser/yamlparser"
+	_ "github.com/k8s-practice/octopus/config/parser/jsonparser"
+	_ "github.com/k8s-practice/octopus/config/parser/tomlparser"
+	_ "github.com/k8s-practice/octopus/config/parser/yamlparser"
 )
 
 type SqlConfig struct {
@@ -69,45 +67,19 @@ func main() {
 
 var configfile = flag.String("c", "./config.toml", "Config file path.")
 var configformat = flag.String("f", "", "Config file format.")
+var _ = flag.String(config.FLAG_PROFILE, "", "Config profile, e.g. dev or prod.")
 
-// InitConfig initialize base config from local file.
+// loadConfig loads config.toml overlaid by config.<profile>.toml and
+// config.local.toml.
 func loadConfig() config.Config {
-	format := *configformat
-	if format == "" {
-		suffix := filepath.Ext(*configfile)
-		format = strings.ToLower(strings.TrimPrefix(suffix, "."))
-	}
-
-	switch {
-	case tomlparser.IsMatchFormat(format):
-		if c, err := config.New(config.T().WithScheme(localfile.Scheme()).
-			WithPath(*configfile).
-			WithFormat(format)); err != nil {
-			log.Panic(err)
-		} else {
-			return c
-		}
-	case yamlparser.IsMatchFormat(format):
-		if c, err := config.New(config.T().WithScheme(localfile.Scheme()).
-			WithPath(*configfile).
-			WithFormat(format)); err != nil {
-			log.Panic(err)
-		} else {
-			return c
-		}
-	case jsonparser.IsMatchFormat(format):
-		if c, err := config.New(config.T().WithScheme(localfile.Scheme()).
-			WithPath(*configfile).
-			WithFormat(format)); err != nil {
-			log.Panic(err)
-		} else {
-			return c
-		}
-	default:
-		log.Panic("Unknown config file format.")
+	c, err := config.NewProfile(config.T().WithScheme(localfile.Scheme()).
+		WithPath(*configfile).
+		WithFormat(*configformat), config.ActiveProfile())
+	if err != nil {
+		log.Panic(err)
 	}
 
-	return nil
+	return c
 }
 
 type Wrapper struct {
@@ -1,16 +1,25 @@
 package main
 
 import (
+	"flag"
 	"sync"
 	"time"
 
 	"github.com/k8s-practice/octopus/xlog"
 )
 
+var async = flag.Bool("async", false, "Write logs asynchronously.")
+
 func init() {
 }
 
 func main() {
+	flag.Parse()
+	if *async {
+		xlog.Default().Async(xlog.AsyncConfig{})
+		defer xlog.Default().Close()
+	}
+
 	var wg sync.WaitGroup
 	for i := 100000; i > 0; i-- {
 		wg.Add(1)
@@ -1,49 +1,405 @@
 package configsearch
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/k8s-practice/octopus/utils/cast"
 )
 
+const (
+	// Wildcard is the path segment which matches all elements.
+	Wildcard = "*"
+
+	// escape escapes the delimiter in key, e.g. "hosts.a\.b\.com".
+	escape = '\\'
+)
+
+// SplitKey splits key into path by delim, the delimiter preceded by a
+// backslash is kept in the segment. A double backslash stands for a
+// backslash.
+func SplitKey(key, delim string) []string {
+	if delim == "" || !strings.ContainsRune(key, escape) {
+		return strings.Split(key, delim)
+	}
+
+	var path []string
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == escape && i+1 < len(key) && key[i+1] == escape:
+			b.WriteByte(escape)
+			i++
+		case key[i] == escape && strings.HasPrefix(key[i+1:], delim):
+			b.WriteString(delim)
+			i += len(delim)
+		case strings.HasPrefix(key[i:], delim):
+			path = append(path, b.String())
+			b.Reset()
+			i += len(delim) - 1
+		default:
+			b.WriteByte(key[i])
+		}
+	}
+
+	return append(path, b.String())
+}
+
 // SearchPathInMap recursively searches for value for path in m map.
+// A path segment which is an integer indexes into a slice,
+// e.g. []string{"servers", "0", "host"}.
+// A Wildcard segment matches all elements of a map or a slice, the matched
+// values are returned as []interface{}, e.g. []string{"servers", "*", "host"}.
 // Returns nil if not found.
 func SearchPathInMap(m map[string]interface{}, path []string) interface{} {
+	return search(m, path, false)
+}
+
+// SearchPathInMapFold is like SearchPathInMap, but matches keys
+// case-insensitively if there is no exactly matched key.
+func SearchPathInMapFold(m map[string]interface{}, path []string) interface{} {
+	return search(m, path, true)
+}
+
+func search(m map[string]interface{}, path []string, fold bool) interface{} {
 	if len(path) == 0 || (len(path) == 1 && path[0] == "") {
 		return m
 	}
 
-	if v, ok := m[path[0]]; ok {
-		if len(path) == 1 {
-			return v
+	return walk(m, path, fold)
+}
+
+func walk(v interface{}, path []string, fold bool) interface{} {
+	for i, key := range path {
+		if key == Wildcard {
+			return walkAll(v, path[i+1:], fold)
 		}
 
-		switch v.(type) {
+		switch node := v.(type) {
 		// Nested case.
 		case map[string]interface{}:
-			return SearchPathInMap(v.(map[string]interface{}), path[1:])
+			v = lookup(node, key, fold)
 		case map[interface{}]interface{}:
-			return SearchPathInMap(cast.ToStringMap(v), path[1:])
+			v = lookup(cast.ToStringMap(node), key, fold)
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
 		default:
 			// Keywords that are not strings are not support.
 			return nil
 		}
+
+		if v == nil {
+			return nil
+		}
+	}
+
+	return v
+}
+
+// walkAll walks path from every child of v, and collects the found values.
+// Values found by nested wildcards are flattened.
+func walkAll(v interface{}, path []string, fold bool) interface{} {
+	var children []interface{}
+	switch node := v.(type) {
+	case map[string]interface{}:
+		children = sortedValues(node)
+	case map[interface{}]interface{}:
+		children = sortedValues(cast.ToStringMap(node))
+	case []interface{}:
+		children = node
+	default:
+		return nil
+	}
+
+	nested := hasWildcard(path)
+	var values []interface{}
+	for _, child := range children {
+		r := walk(child, path, fold)
+		if r == nil {
+			continue
+		}
+		if nested {
+			values = append(values, r.([]interface{})...)
+		} else {
+			values = append(values, r)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values
+}
+
+func lookup(m map[string]interface{}, key string, fold bool) interface{} {
+	if v, ok := m[key]; ok || !fold {
+		return v
+	}
+
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
 	}
 
 	return nil
 }
 
+// sortedValues returns values of m in order of keys.
+func sortedValues(m map[string]interface{}) []interface{} {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	values := make([]interface{}, 0, len(m))
+	for _, k := range keys {
+		values = append(values, m[k])
+	}
+
+	return values
+}
+
+func hasWildcard(path []string) bool {
+	for _, key := range path {
+		if key == Wildcard {
+			return true
+		}
+	}
+
+	return false
+}
+
 // SetValueInMap sets value on the path in m map.
-func SetValueInMap(m map[string]interface{}, path []string, value interface{}) {
-	for _, key := range path[0 : len(path)-1] {
-		if m2, ok := m[key]; ok {
-			if m3, ok := m2.(map[string]interface{}); ok {
-				m = m3
+// The missing maps on the path are created, and non-map values on the path
+// are replaced by maps. An integer segment indexes into a slice, the index
+// equals to the length of the slice appends to it. A Wildcard segment sets
+// value on all present elements.
+func SetValueInMap(m map[string]interface{}, path []string, value interface{}) error {
+	if len(path) == 0 {
+		return errors.New("Empty path.")
+	}
+
+	_, err := set(m, path, value)
+	return err
+}
+
+// set sets value on the path in node, returns the node which should replace
+// the original one.
+func set(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	var err error
+	key := path[0]
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if key == Wildcard {
+			for k, child := range n {
+				if n[k], err = set(child, path[1:], value); err != nil {
+					return n, err
+				}
 			}
-		} else {
-			m3 := make(map[string]interface{})
-			m[key] = m3
-			m = m3
+			return n, nil
+		}
+		n[key], err = set(n[key], path[1:], value)
+		return n, err
+	case map[interface{}]interface{}:
+		if key == Wildcard {
+			for k, child := range n {
+				if n[k], err = set(child, path[1:], value); err != nil {
+					return n, err
+				}
+			}
+			return n, nil
+		}
+		n[key], err = set(n[key], path[1:], value)
+		return n, err
+	case []interface{}:
+		if key == Wildcard {
+			for i, child := range n {
+				if n[i], err = set(child, path[1:], value); err != nil {
+					return n, err
+				}
+			}
+			return n, nil
+		}
+		i, e := strconv.Atoi(key)
+		switch {
+		case e != nil || i < 0 || i > len(n):
+			return n, fmt.Errorf("Invalid index [%s] of slice with length %d.", key, len(n))
+		case i == len(n):
+			var child interface{}
+			child, err = set(nil, path[1:], value)
+			return append(n, child), err
+		default:
+			n[i], err = set(n[i], path[1:], value)
+			return n, err
+		}
+	default:
+		if key == Wildcard {
+			// Nothing matches.
+			return node, nil
 		}
+		m := make(map[string]interface{})
+		m[key], err = set(nil, path[1:], value)
+		return m, err
+	}
+}
+
+// DeleteValueInMap deletes the value on the path in m map.
+// An integer segment indexes into a slice, the element is removed from the
+// slice. A Wildcard segment deletes on all present elements.
+// Deleting a missing path is not an error.
+func DeleteValueInMap(m map[string]interface{}, path []string) error {
+	if len(path) == 0 {
+		return errors.New("Empty path.")
 	}
 
-	m[path[len(path)-1]] = value
+	_, err := del(m, path)
+	return err
+}
+
+// del deletes the path in node, returns the node which should replace
+// the original one.
+func del(node interface{}, path []string) (interface{}, error) {
+	var err error
+	key, last := path[0], len(path) == 1
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k, child := range n {
+			if k != key && key != Wildcard {
+				continue
+			}
+			if last {
+				delete(n, k)
+			} else if n[k], err = del(child, path[1:]); err != nil {
+				return n, err
+			}
+		}
+		return n, nil
+	case map[interface{}]interface{}:
+		for k, child := range n {
+			if cast.ToString(k) != key && key != Wildcard {
+				continue
+			}
+			if last {
+				delete(n, k)
+			} else if n[k], err = del(child, path[1:]); err != nil {
+				return n, err
+			}
+		}
+		return n, nil
+	case []interface{}:
+		if key == Wildcard {
+			if last {
+				return n[:0], nil
+			}
+			for i, child := range n {
+				if n[i], err = del(child, path[1:]); err != nil {
+					return n, err
+				}
+			}
+			return n, nil
+		}
+		i, e := strconv.Atoi(key)
+		switch {
+		case e != nil || i < 0:
+			return n, fmt.Errorf("Invalid index [%s] of slice with length %d.", key, len(n))
+		case i >= len(n):
+			return n, nil
+		case last:
+			return append(n[:i:i], n[i+1:]...), nil
+		default:
+			n[i], err = del(n[i], path[1:])
+			return n, err
+		}
+	default:
+		return node, nil
+	}
+}
+
+// Copy returns a deep copy of v, the nested maps and slices are copied.
+func Copy(v interface{}) interface{} {
+	switch n := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(n))
+		for k, child := range n {
+			m[k] = Copy(child)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(n))
+		for k, child := range n {
+			m[k] = Copy(child)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(n))
+		for i, child := range n {
+			s[i] = Copy(child)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// Merge deeply merges src into dst if both are map[string]interface{}, and
+// returns dst. Otherwise src replaces dst, unless src is nil.
+func Merge(dst, src interface{}) interface{} {
+	dm, ok1 := dst.(map[string]interface{})
+	sm, ok2 := src.(map[string]interface{})
+	if !ok1 || !ok2 {
+		if src == nil {
+			return dst
+		}
+		return src
+	}
+
+	for k, v := range sm {
+		dm[k] = Merge(dm[k], v)
+	}
+
+	return dm
+}
+
+// InsertValueInMap is like SetValueInMap, but only creates maps on the path,
+// and fails if the path conflicts with the present values, e.g. inserting
+// "a.b" while "a" is a string, or inserting "a" while "a" is a map.
+func InsertValueInMap(m map[string]interface{}, path []string, value interface{}) error {
+	if len(path) == 0 {
+		return errors.New("Empty path.")
+	}
+
+	for i, key := range path[:len(path)-1] {
+		switch v := m[key].(type) {
+		case nil:
+			m2 := make(map[string]interface{})
+			m[key] = m2
+			m = m2
+		case map[string]interface{}:
+			m = v
+		default:
+			return fmt.Errorf("Key [%s] conflicts with value %v.", strings.Join(path[:i+1], "."), v)
+		}
+	}
+
+	key := path[len(path)-1]
+	if _, ok := m[key].(map[string]interface{}); ok {
+		return fmt.Errorf("Key [%s] conflicts with nested keys.", strings.Join(path, "."))
+	}
+	m[key] = value
+
+	return nil
 }
@@ -0,0 +1,58 @@
+package configsearch
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	// DurationType and TimeType are decoded from strings, they are leaves
+	// rather than structs.
+	DurationType = reflect.TypeOf(time.Duration(0))
+	TimeType     = reflect.TypeOf(time.Time{})
+)
+
+// Field is a field of struct decoded by mapstructure.
+type Field struct {
+	reflect.StructField
+
+	// Key is the name in mapstructure tag, or the lower case of the field
+	// name.
+	Key string
+
+	// Squash reports whether the fields of the embedded struct are
+	// squashed into the parent, by embedding or the tag option "squash".
+	Squash bool
+}
+
+// Fields returns the fields of struct t decoded by mapstructure, the
+// unexported fields and the fields tagged with "-" are skipped.
+func Fields(t reflect.Type) []Field {
+	fields := make([]Field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			// Unexported field.
+			continue
+		}
+
+		tag := strings.Split(f.Tag.Get("mapstructure"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+
+		field := Field{StructField: f, Key: tag[0], Squash: f.Anonymous}
+		if field.Key == "" {
+			field.Key = strings.ToLower(f.Name)
+		}
+		for _, opt := range tag[1:] {
+			if opt == "squash" {
+				field.Squash = true
+			}
+		}
+		fields = append(fields, field)
+	}
+
+	return fields
+}
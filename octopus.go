@@ -1,7 +1,12 @@
 package octopus
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/internal/configsearch"
+	"github.com/k8s-practice/octopus/utils/cast"
 	"github.com/mitchellh/mapstructure"
 )
 
@@ -32,9 +37,93 @@ func (o *Octopus) WithConfig(c config.Config) *Octopus {
 }
 
 // Load loads configuration by key from Octopus.conf .
+// The strings are converted into time.Duration and time.Time fields.
+// If Octopus.conf records the keys read, e.g. config.Auditor, the fields of
+// i are recorded, the fields absent in the configuration are missing.
 func (o *Octopus) Load(key string, i interface{}) error {
-	err := mapstructure.Decode(o.config.Get(key), i)
-	return err
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: decodeHook,
+		Result:     i,
+	})
+	if err != nil {
+		return err
+	}
+
+	r, recording := o.config.(config.ReadRecorder)
+	if !recording {
+		return decoder.Decode(o.config.Get(key))
+	}
+
+	data := r.Peek(key)
+	if err = decoder.Decode(data); err != nil || data == nil {
+		r.Record(key, data != nil)
+		return err
+	}
+
+	m, ok := data.(map[string]interface{})
+	paths := fieldPaths(reflect.TypeOf(i), nil, nil)
+	if !ok || len(paths) == 0 {
+		r.Record(key, true)
+		return nil
+	}
+	for _, path := range paths {
+		k := strings.Join(path, config.DEFAULT_KEY_DELIMITER)
+		if key != "" {
+			k = key + config.DEFAULT_KEY_DELIMITER + k
+		}
+		r.Record(k, configsearch.SearchPathInMapFold(m, path) != nil)
+	}
+
+	return nil
+}
+
+// fieldPaths returns the paths of the leaf fields of struct t decoded by
+// mapstructure, the slices and maps are leaves. visiting breaks the
+// recursive types.
+func fieldPaths(t reflect.Type, prefix []string, visiting []reflect.Type) [][]string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == configsearch.TimeType {
+		return nil
+	}
+	for _, v := range visiting {
+		if v == t {
+			return nil
+		}
+	}
+	visiting = append(visiting, t)
+
+	var paths [][]string
+	for _, f := range configsearch.Fields(t) {
+		path := append(prefix[:len(prefix):len(prefix)], f.Key)
+		if f.Squash {
+			path = prefix
+		}
+		if nested := fieldPaths(f.Type, path, visiting); len(nested) > 0 {
+			paths = append(paths, nested...)
+		} else if !f.Squash {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// decodeHook converts strings into time.Duration and time.Time.
+func decodeHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String {
+		return data, nil
+	}
+
+	switch to {
+	case configsearch.DurationType:
+		return cast.ToDurationE(data)
+	case configsearch.TimeType:
+		return cast.ToTimeE(data)
+	default:
+		return data, nil
+	}
 }
 
 func (o *Octopus) Run() error {
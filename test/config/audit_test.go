@@ -0,0 +1,77 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/k8s-practice/octopus"
+	"github.com/k8s-practice/octopus/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	a := config.Audit(newConfig(t, memoryTarget(map[string]interface{}{
+		"database": map[string]interface{}{
+			"addr": "a",
+			"port": 3306,
+			"info": map[string]interface{}{"user": "root", "password": "x"},
+		},
+		"log": map[string]interface{}{"level": "info", "path": "/var/log"},
+		"servers": []interface{}{
+			map[string]interface{}{"host": "h1"},
+		},
+		"legacy": map[string]interface{}{},
+	})))
+
+	assert.Equal(t, "info", config.GetString(a, "log.level"))
+	assert.Nil(t, config.Get(a, "cache.size"))
+	assert.Equal(t, []string{"h1"}, config.GetStringSlice(a, "servers.*.host"))
+
+	var db struct {
+		Addr string
+		Info struct {
+			User string
+		}
+	}
+	assert.Nil(t, octopus.New().WithConfig(a).Load("database", &db))
+	assert.Equal(t, "root", db.Info.User)
+
+	report := a.Report()
+	assert.Equal(t, []string{"database.info.password", "database.port", "legacy", "log.path"}, report.Unused)
+	assert.Equal(t, []string{"cache.size"}, report.Missing)
+
+	// A key read as a whole covers the nested keys.
+	config.Get(a, "database")
+	assert.Equal(t, []string{"legacy", "log.path"}, a.Report().Unused)
+
+	a.Reset()
+	assert.Empty(t, a.Report().Missing)
+	assert.Nil(t, config.Set(a, "log.path", "/tmp"))
+}
+
+func TestAuditWrapped(t *testing.T) {
+	a := config.Audit(newConfig(t, memoryTarget(map[string]interface{}{
+		"database": map[string]interface{}{"addr": "local"},
+	})))
+	base := newConfig(t, memoryTarget(map[string]interface{}{
+		"database": map[string]interface{}{"addr": "base", "port": 3306, "type": "mysql"},
+	}))
+
+	var db struct {
+		Addr    string
+		Port    int
+		Timeout time.Duration
+		Info    *struct {
+			User string `mapstructure:"user_name"`
+		}
+	}
+	assert.Nil(t, octopus.New().WithConfig(config.MultiConfig(a, base)).Load("database", &db))
+	assert.Equal(t, "local", db.Addr)
+	assert.Equal(t, 3306, db.Port)
+
+	// The records are forwarded to the wrapped Auditor, the fields absent
+	// in the configuration are missing.
+	report := a.Report()
+	assert.Equal(t, []string{"database.info.user_name", "database.timeout"}, report.Missing)
+	assert.Empty(t, report.Unused)
+}
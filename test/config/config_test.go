@@ -7,6 +7,7 @@ import (
 
 	// NOTE: Import these packages while using them, for reducing program size.
 	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource"
 	"github.com/k8s-practice/octopus/config/datasource/localfile"
 	"github.com/k8s-practice/octopus/config/parser/jsonparser"
 	"github.com/k8s-practice/octopus/config/parser/tomlparser"
@@ -18,6 +19,13 @@ func init() {
 	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds | log.Llongfile)
 }
 
+func newConfig(t *testing.T, target datasource.Target, opts ...config.Option) config.Config {
+	c, err := config.New(target, opts...)
+	assert.Nil(t, err, "Must be successful.")
+
+	return c
+}
+
 func TestNewConfig(t *testing.T) {
 	c1, err := config.New(
 		config.T().WithScheme(localfile.Scheme()).
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource/localfile"
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectByHint(t *testing.T) {
+	format, err := parser.Detect(nil, "YAML")
+	assert.Nil(t, err)
+	assert.Equal(t, "yaml", format)
+
+	format, err = parser.Detect(nil, "/etc/app/app.yml")
+	assert.Nil(t, err)
+	assert.Equal(t, "yml", format)
+
+	format, err = parser.Detect([]byte(`{"a": 1}`), "/etc/app/app.conf")
+	assert.Nil(t, err)
+	assert.Equal(t, "json", format)
+}
+
+func TestDetectByContent(t *testing.T) {
+	cases := map[string]string{
+		"json":  `{"database": {"addr": "a"}}`,
+		"toml":  "[database]\naddr = \"a\"\nport = 3306\n",
+		"hcl":   "database {\n  addr = \"a\"\n}\n",
+		"yaml":  "database:\n  addr: a\n",
+		"json5": "{\n  // comment\n  database: {addr: 'a',},\n}\n",
+		"ini":   "[database]\naddr = a\n",
+	}
+
+	for want, data := range cases {
+		format, err := parser.Detect([]byte(data), "")
+		assert.Nil(t, err, want)
+		assert.Equal(t, want, format)
+	}
+
+	_, err := parser.Detect([]byte("# only comments\n"), "")
+	assert.NotNil(t, err)
+}
+
+func TestDetectLocalFile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "octopus")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app")
+	assert.Nil(t, os.WriteFile(path, []byte("database:\n  addr: a\n"), 0644))
+
+	c, err := config.New(config.T().WithScheme(localfile.Scheme()).WithPath(path))
+	assert.Nil(t, err, "Must be successful.")
+	assert.Equal(t, "a", config.GetString(c, "database.addr"))
+
+	path = filepath.Join(dir, "app.toml")
+	assert.Nil(t, os.WriteFile(path, []byte("[database]\naddr = \"b\"\n"), 0644))
+
+	c, err = config.New(config.T().WithScheme(localfile.Scheme()).WithPath(path))
+	assert.Nil(t, err, "Must be successful.")
+	assert.Equal(t, "b", config.GetString(c, "database.addr"))
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/k8s-practice/octopus/config/parser/jsonparser"
+	"github.com/k8s-practice/octopus/config/parser/tomlparser"
+	"github.com/k8s-practice/octopus/config/parser/yamlparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseFile(t *testing.T, file, format string) map[string]interface{} {
+	data, err := os.ReadFile(file)
+	assert.Nil(t, err)
+
+	m := make(map[string]interface{})
+	assert.Nil(t, parser.Parse(format, data, &m))
+
+	return m
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	cases := []struct {
+		file   string
+		format string
+	}{
+		{"./p1.toml", tomlparser.Format()},
+		{"./p2.yaml", yamlparser.Format()},
+		{"./p3.json", jsonparser.Format()},
+	}
+
+	for _, c := range cases {
+		m := parseFile(t, c.file, c.format)
+
+		data, err := parser.Marshal(c.format, m)
+		assert.Nil(t, err, c.file)
+
+		m2 := make(map[string]interface{})
+		assert.Nil(t, parser.Parse(c.format, data, &m2), c.file)
+		assert.Equal(t, m, m2, c.file)
+	}
+}
+
+func TestMarshalConvert(t *testing.T) {
+	m := parseFile(t, "./p3.json", jsonparser.Format())
+
+	for _, format := range []string{tomlparser.Format(), yamlparser.Format()} {
+		data, err := parser.Marshal(format, m)
+		assert.Nil(t, err, format)
+
+		m2 := make(map[string]interface{})
+		assert.Nil(t, parser.Parse(format, data, &m2), format)
+
+		data, err = parser.Marshal(jsonparser.Format(), m2)
+		assert.Nil(t, err, format)
+
+		m3 := make(map[string]interface{})
+		assert.Nil(t, parser.Parse(jsonparser.Format(), data, &m3), format)
+		assert.Equal(t, m, m3, format)
+	}
+
+	_, err := parser.Marshal("unknown", m)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource/localfile"
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/k8s-practice/octopus/config/parser/hclparser"
+	"github.com/k8s-practice/octopus/config/parser/json5parser"
+	"github.com/k8s-practice/octopus/config/parser/jsonparser"
+	"github.com/k8s-practice/octopus/config/parser/tomlparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseError(t *testing.T, format, data string) *parser.ParseError {
+	m := make(map[string]interface{})
+	err := parser.Parse(format, []byte(data), &m)
+
+	var pe *parser.ParseError
+	assert.True(t, errors.As(err, &pe), "Must be ParseError.")
+
+	return pe
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	pe := parseError(t, jsonparser.Format(), "{\n  \"a\": 1,\n  \"b\": }\n")
+	assert.Equal(t, "json", pe.Format)
+	assert.Equal(t, 3, pe.Line)
+	assert.Equal(t, 8, pe.Column)
+	assert.Equal(t, `  "b": }`, pe.Snippet)
+
+	// The position is in the JSON5 source rather than the converted JSON.
+	pe = parseError(t, json5parser.Format(), "{\n  // comment\n  a: 'x',\n  b: }\n")
+	assert.Equal(t, 4, pe.Line)
+	assert.Equal(t, 6, pe.Column)
+
+	pe = parseError(t, json5parser.Format(), "{\n  a: NaN,\n}")
+	assert.Equal(t, 2, pe.Line)
+	assert.Equal(t, 6, pe.Column)
+
+	pe = parseError(t, tomlparser.Format(), "a = 1\nb = \n")
+	assert.Equal(t, 2, pe.Line)
+
+	pe = parseError(t, hclparser.Format(), "a = 1\nb = {\n")
+	assert.Equal(t, "hcl", pe.Format)
+	assert.Greater(t, pe.Line, 0)
+}
+
+func TestParseErrorMessage(t *testing.T) {
+	pe := parseError(t, jsonparser.Format(), "{\n\t\"a\": x}")
+	assert.Equal(t,
+		"Failed to parse json at line 2, column 7: invalid character 'x' looking for beginning of value\n"+
+			"    2 | \t\"a\": x}\n"+
+			"      | \t     ^",
+		pe.Error())
+}
+
+func TestParseErrorSource(t *testing.T) {
+	dir, err := os.MkdirTemp("", "octopus")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "broken.json")
+	assert.Nil(t, os.WriteFile(path, []byte(`{"a": }`), 0644))
+
+	_, err = config.New(config.T().WithScheme(localfile.Scheme()).WithPath(path).WithFormat(jsonparser.Format()))
+
+	var pe *parser.ParseError
+	assert.True(t, errors.As(err, &pe), "Must be ParseError.")
+	assert.Equal(t, path, pe.Source)
+	assert.Equal(t, 1, pe.Line)
+	assert.Equal(t, 7, pe.Column)
+	assert.Contains(t, err.Error(), "["+path+"]")
+}
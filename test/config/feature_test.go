@@ -0,0 +1,78 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/feature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeature(t *testing.T) {
+	c := newConfig(t, memoryTarget(map[string]interface{}{
+		"features": map[string]interface{}{
+			"new-ui":    true,
+			"old-ui":    "false",
+			"fast-path": 30,
+			"canary":    "1",
+			"disabled":  "0",
+			"checkout-v2": map[string]interface{}{
+				"rollout": 0,
+				"bucket":  feature.ATTR_TENANT,
+				"rules": []interface{}{
+					map[string]interface{}{
+						"match":   map[string]interface{}{feature.ATTR_REGION: []interface{}{"eu-west", "eu-north"}},
+						"rollout": 100,
+					},
+					map[string]interface{}{
+						"match":   map[string]interface{}{feature.ATTR_TENANT: "42"},
+						"rollout": 100,
+					},
+				},
+			},
+		},
+	}))
+	f := feature.New(c)
+
+	assert.True(t, f.Enabled("new-ui", nil))
+	assert.False(t, f.Enabled("old-ui", nil))
+	assert.False(t, f.Enabled("unknown", nil))
+
+	assert.Equal(t, []string{"canary", "checkout-v2", "disabled", "fast-path", "new-ui", "old-ui"}, f.Names())
+
+	assert.True(t, f.Enabled("checkout-v2", feature.Attributes{feature.ATTR_REGION: "eu-west"}))
+	assert.True(t, f.Enabled("checkout-v2", feature.Attributes{feature.ATTR_REGION: "us-east", feature.ATTR_TENANT: "42"}))
+	assert.False(t, f.Enabled("checkout-v2", feature.Attributes{feature.ATTR_REGION: "us-east", feature.ATTR_TENANT: "7"}))
+
+	// The rollout is stable and roughly proportional.
+	enabled := 0
+	for i := 0; i < 10000; i++ {
+		attrs := feature.Attributes{feature.ATTR_USER: fmt.Sprint(i)}
+		if f.Enabled("fast-path", attrs) {
+			enabled++
+			assert.True(t, f.Enabled("fast-path", attrs))
+		}
+	}
+	assert.InDelta(t, 3000, enabled, 300)
+	assert.False(t, f.Enabled("fast-path", nil))
+
+	// The numeric strings are rollout percentages rather than bools, "1" is
+	// a 1% rollout.
+	canary, disabled := 0, 0
+	for i := 0; i < 10000; i++ {
+		attrs := feature.Attributes{feature.ATTR_USER: fmt.Sprint(i)}
+		if f.Enabled("canary", attrs) {
+			canary++
+		}
+		if f.Enabled("disabled", attrs) {
+			disabled++
+		}
+	}
+	assert.InDelta(t, 100, canary, 50)
+	assert.Equal(t, 0, disabled)
+
+	// Hot update.
+	assert.Nil(t, config.Set(c, "features.new-ui", false))
+	assert.False(t, f.Enabled("new-ui", nil))
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource"
+	"github.com/k8s-practice/octopus/config/datasource/localfile"
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/k8s-practice/octopus/config/parser/dotenvparser"
+	"github.com/k8s-practice/octopus/config/parser/hclparser"
+	"github.com/k8s-practice/octopus/config/parser/iniparser"
+	"github.com/k8s-practice/octopus/config/parser/propertiesparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func fileTarget(path, format string) datasource.Target {
+	return config.T().WithScheme(localfile.Scheme()).
+		WithPath(path).
+		WithFormat(format)
+}
+
+func TestHCL(t *testing.T) {
+	c := newConfig(t, fileTarget("./p4.hcl", hclparser.Format()))
+
+	assert.Equal(t, "172.168.0.4", config.GetString(c, "database.addr"))
+	assert.Equal(t, 3309, config.GetInt(c, "database.port"))
+	assert.Equal(t, "172.168.0.41", config.GetString(c, "database.replica.r1.addr"))
+	assert.Equal(t, "172.168.0.42", config.GetString(c, "database.replica.r2.addr"))
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, config.GetStringSlice(c, "servers.*.host"))
+}
+
+func TestINI(t *testing.T) {
+	c := newConfig(t, fileTarget("./p5.ini", iniparser.Format()))
+
+	assert.Equal(t, "myapp", config.GetString(c, "name"))
+	assert.Equal(t, "172.168.0.5", config.GetString(c, "database.addr"))
+	assert.Equal(t, 3310, config.GetInt(c, "database.port"))
+	assert.Equal(t, "root ; not a comment", config.GetString(c, "database.info.user"))
+	assert.Equal(t, "172.168.0.51", config.GetString(c, "database.replica.r1.addr"))
+}
+
+func TestDotenv(t *testing.T) {
+	c := newConfig(t, fileTarget("./p6.env", dotenvparser.Format()))
+
+	assert.Equal(t, "myapp", config.GetString(c, "NAME"))
+	assert.Equal(t, "172.168.0.6", config.GetString(c, "database.addr"))
+	assert.Equal(t, 3311, config.GetInt(c, "database.port"))
+	assert.Equal(t, "hello\nworld", config.GetString(c, "GREETING"))
+}
+
+func TestProperties(t *testing.T) {
+	c := newConfig(t, fileTarget("./p7.properties", propertiesparser.Format()))
+
+	assert.Equal(t, "172.168.0.7", config.GetString(c, "database.addr"))
+	assert.Equal(t, 3312, config.GetInt(c, "database.port"))
+	assert.Equal(t, "root", config.GetString(c, "database.info.user"))
+	assert.Equal(t, "你好", config.GetString(c, "greeting"))
+}
+
+func TestFormatsConflict(t *testing.T) {
+	m := make(map[string]interface{})
+	err := parser.Parse(propertiesparser.Format(), []byte("log.level = info\nlog.level.root = debug\n"), &m)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+
+	err = parser.Parse(iniparser.Format(), []byte("[database\n"), &m)
+	assert.Contains(t, err.Error(), "line 1")
+}
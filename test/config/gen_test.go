@@ -0,0 +1,72 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/k8s-practice/octopus"
+	"github.com/k8s-practice/octopus/config/gen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	tree := map[string]interface{}{
+		"database": map[string]interface{}{
+			"db_name": "octopus",
+			"port":    float64(3306),
+			"timeout": "5s",
+			"created": "2021-03-19 14:15:16",
+			"ratio":   0.5,
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a"},
+			map[string]interface{}{"host": "b", "weight": 2},
+		},
+		"tags": []interface{}{"x", "y"},
+	}
+
+	code, err := gen.Generate(tree, "conf", "app")
+	assert.Nil(t, err)
+	assert.Equal(t, `// Code generated by octopus-config gen. DO NOT EDIT.
+
+package conf
+
+import "time"
+
+type App struct {
+	Database AppDatabase  `+"`mapstructure:\"database\"`"+`
+	Servers  []AppServers `+"`mapstructure:\"servers\"`"+`
+	Tags     []string     `+"`mapstructure:\"tags\"`"+`
+}
+
+type AppDatabase struct {
+	Created time.Time     `+"`mapstructure:\"created\"`"+`
+	DbName  string        `+"`mapstructure:\"db_name\"`"+`
+	Port    int           `+"`mapstructure:\"port\"`"+`
+	Ratio   float64       `+"`mapstructure:\"ratio\"`"+`
+	Timeout time.Duration `+"`mapstructure:\"timeout\"`"+`
+}
+
+type AppServers struct {
+	Host   string `+"`mapstructure:\"host\"`"+`
+	Weight int    `+"`mapstructure:\"weight\"`"+`
+}
+`, string(code))
+}
+
+func TestLoadDurationAndTime(t *testing.T) {
+	c := newConfig(t, memoryTarget(map[string]interface{}{
+		"database": map[string]interface{}{
+			"timeout": "5s",
+			"created": "2021-03-19 14:15:16",
+		},
+	}))
+
+	var db struct {
+		Timeout time.Duration
+		Created time.Time
+	}
+	assert.Nil(t, octopus.New().WithConfig(c).Load("database", &db))
+	assert.Equal(t, 5*time.Second, db.Timeout)
+	assert.Equal(t, 2021, db.Created.Year())
+}
@@ -0,0 +1,101 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/k8s-practice/octopus/config/parser/json5parser"
+	"github.com/k8s-practice/octopus/config/parser/jsonparser"
+	"github.com/stretchr/testify/assert"
+)
+
+const databaseJSON5 = `
+// Database of myapp.
+{
+    database: {
+        type: 'mysql',      /* single quoted */
+        "addr": "172.168.0.8",
+        port: 0xCF0,
+        weight: .5,
+        name: 'it\'s "db"',
+        id: 1234567890123456789,
+        tags: ['a', 'b',],
+    },
+}
+`
+
+func TestJSON5(t *testing.T) {
+	m := make(map[string]interface{})
+	assert.Nil(t, parser.Parse(json5parser.Format(), []byte(databaseJSON5), &m))
+
+	c := configOf(m)
+	assert.Equal(t, "mysql", config.GetString(c, "database.type"))
+	assert.Equal(t, 3312, config.GetInt(c, "database.port"))
+	assert.Equal(t, 0.5, config.GetFloat64(c, "database.weight"))
+	assert.Equal(t, `it's "db"`, config.GetString(c, "database.name"))
+	assert.Equal(t, []string{"a", "b"}, config.GetStringSlice(c, "database.tags"))
+
+	for _, data := range []string{`{a: Infinity}`, `{a: b}`, `{a: 'b}`, `{/* a: 1}`} {
+		assert.NotNil(t, parser.Parse("jsonc", []byte(data), &m), data)
+	}
+}
+
+func TestJSONUseNumber(t *testing.T) {
+	defer parser.Register(json5parser.NewBuilder())
+	defer parser.Register(jsonparser.NewBuilder())
+
+	parser.Register(json5parser.NewBuilder(json5parser.WithUseNumber()))
+	parser.Register(jsonparser.NewBuilder(jsonparser.WithUseNumber()))
+
+	m := make(map[string]interface{})
+	assert.Nil(t, parser.Parse(json5parser.Format(), []byte(databaseJSON5), &m))
+	c := configOf(m)
+	assert.Equal(t, json.Number("1234567890123456789"), config.Get(c, "database.id"))
+	assert.Equal(t, int64(1234567890123456789), config.GetInt64(c, "database.id"))
+
+	m = make(map[string]interface{})
+	assert.Nil(t, parser.Parse(jsonparser.Format(), []byte(`{"id": 1234567890123456789}`), &m))
+	assert.Equal(t, int64(1234567890123456789), config.GetInt64(configOf(m), "id"))
+
+	assert.NotNil(t, parser.Parse(jsonparser.Format(), []byte(`{"id": 1} {}`), &m))
+}
+
+// mapConfig implements config.Config for the parsed data.
+type mapConfig map[string]interface{}
+
+func (m mapConfig) Get(key string) interface{} {
+	var v interface{} = map[string]interface{}(m)
+	for _, k := range strings.Split(key, ".") {
+		mm, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = mm[k]
+	}
+
+	return v
+}
+
+func configOf(m map[string]interface{}) config.Config {
+	return mapConfig(m)
+}
+
+func TestJSON5Escape(t *testing.T) {
+	data := `{a: '\x41\x7e', b: 'x\0y', c: '\v', d: '\a\'\"', e: "tab\tnew\nline"}`
+	m := make(map[string]interface{})
+	assert.Nil(t, parser.Parse(json5parser.Format(), []byte(data), &m))
+	assert.Equal(t, map[string]interface{}{
+		"a": "A~",
+		"b": "x\x00y",
+		"c": "\v",
+		"d": `a'"`,
+		"e": "tab\tnew\nline",
+	}, m)
+
+	for _, data := range []string{`{a: '\x4'}`, `{a: '\xZZ'}`, `{a: '\01'}`, `{a: '\1'}`} {
+		assert.IsType(t, &parser.ParseError{}, parser.Parse(json5parser.Format(), []byte(data), &m), data)
+	}
+}
@@ -0,0 +1,54 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource"
+	"github.com/k8s-practice/octopus/config/parser/yamlparser"
+	"github.com/stretchr/testify/assert"
+)
+
+const hostsYAML = `
+hosts:
+  a.b.com:
+    port: 8080
+Servers:
+  - host: 10.0.0.1
+  - host: 10.0.0.2
+`
+
+func hostsTarget(t *testing.T) datasource.Target {
+	// Path is case-sensitive, TempDir contains the upper case test name.
+	file := filepath.Join(t.TempDir(), "Hosts.yaml")
+	assert.Nil(t, os.WriteFile(file, []byte(hostsYAML), 0644))
+
+	return fileTarget(file, yamlparser.Format())
+}
+
+func TestKeyEscape(t *testing.T) {
+	c := newConfig(t, hostsTarget(t))
+
+	assert.Equal(t, 8080, config.GetInt(c, `hosts.a\.b\.com.port`))
+	assert.Nil(t, config.Get(c, "hosts.a.b.com.port"))
+	assert.Equal(t, "10.0.0.2", config.GetString(c, "Servers.1.host"))
+	assert.Nil(t, config.Get(c, "Servers.2.host"))
+}
+
+func TestKeyDelimiter(t *testing.T) {
+	c := newConfig(t, hostsTarget(t), config.WithDelimiter("/"))
+
+	assert.Equal(t, 8080, config.GetInt(c, "hosts/a.b.com/port"))
+	assert.Equal(t, "10.0.0.1", config.GetString(c, "Servers/0/host"))
+}
+
+func TestKeyCaseInsensitive(t *testing.T) {
+	c := newConfig(t, hostsTarget(t))
+	assert.Nil(t, config.Get(c, "servers.0.host"))
+
+	c = newConfig(t, hostsTarget(t), config.WithCaseInsensitive())
+	assert.Equal(t, "10.0.0.1", config.GetString(c, "servers.0.HOST"))
+	assert.Equal(t, 8080, config.GetInt(c, `HOSTS.A\.B\.COM.Port`))
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource"
+	"github.com/k8s-practice/octopus/config/datasource/memory"
+	"github.com/k8s-practice/octopus/config/parser/tomlparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type defaults struct {
+	Database struct {
+		Addr    string
+		Port    int
+		Timeout time.Duration
+	}
+	Servers []struct {
+		Host string `mapstructure:"host"`
+	} `mapstructure:"servers"`
+}
+
+func memoryTarget(data interface{}) datasource.Target {
+	return config.T().WithScheme(memory.Scheme()).WithValue(memory.KEY_DATA, data)
+}
+
+func TestMemoryStruct(t *testing.T) {
+	var d defaults
+	d.Database.Addr = "127.0.0.1"
+	d.Database.Port = 3306
+	d.Database.Timeout = time.Second
+	d.Servers = append(d.Servers, struct {
+		Host string `mapstructure:"host"`
+	}{"10.0.0.1"})
+
+	c := newConfig(t, memoryTarget(&d))
+	assert.Equal(t, "127.0.0.1", config.GetString(c, "database.addr"))
+	assert.Equal(t, 3306, config.GetInt(c, "database.port"))
+	assert.Equal(t, time.Second, config.GetDuration(c, "database.timeout"))
+	assert.Equal(t, []string{"10.0.0.1"}, config.GetStringSlice(c, "servers.*.host"))
+}
+
+func TestMemoryWritable(t *testing.T) {
+	c := newConfig(t, memoryTarget(map[string]interface{}{
+		"log": map[string]interface{}{"level": "info"},
+	}))
+
+	changed := 0
+	c.(config.Watcher).Watch(func() { changed++ })
+
+	assert.Nil(t, config.Set(c, "log.level", "debug"))
+	assert.Equal(t, "debug", config.GetString(c, "log.level"))
+	assert.Nil(t, config.Delete(c, "log.level"))
+	assert.Nil(t, config.Get(c, "log.level"))
+	assert.Nil(t, config.Persist(c))
+	assert.Equal(t, 2, changed)
+
+	_, err := config.New(config.T().WithScheme(memory.Scheme()).WithValue(memory.KEY_DATA, 1))
+	assert.NotNil(t, err)
+}
+
+func TestMemoryDefaults(t *testing.T) {
+	file := newConfig(t, fileTarget("./p1.toml", tomlparser.Format()))
+	mc := config.MultiConfig(file, newConfig(t, memoryTarget(map[string]interface{}{
+		"database": map[string]interface{}{"info": map[string]interface{}{"addr": "default"}, "pool": 10},
+	})))
+
+	assert.Equal(t, "172.168.0.1", config.GetString(mc, "database.info.addr"))
+	assert.Equal(t, 10, config.GetInt(mc, "database.pool"))
+}
@@ -0,0 +1,18 @@
+database {
+  type = "mysql"
+  addr = "172.168.0.4"
+  port = 3309
+
+  replica "r1" {
+    addr = "172.168.0.41"
+  }
+}
+
+database "replica" "r2" {
+  addr = "172.168.0.42"
+}
+
+servers = [
+  { host = "10.0.0.1" },
+  { host = "10.0.0.2" },
+]
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource/localfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "octopus")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"app.yaml":       "database:\n  addr: base\n  port: 3306\n  user: root\n",
+		"app.prod.yaml":  "database:\n  addr: prod\n  port: 3307\n",
+		"app.local.yaml": "database:\n  addr: local\n",
+	}
+	for name, data := range files {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0644))
+	}
+
+	target := config.T().WithScheme(localfile.Scheme()).WithPath(filepath.Join(dir, "app.yaml"))
+	c, err := config.NewProfile(target, "prod")
+	assert.Nil(t, err, "Must be successful.")
+	assert.Equal(t, "local", config.GetString(c, "database.addr"))
+	assert.Equal(t, 3307, config.GetInt(c, "database.port"))
+	assert.Equal(t, "root", config.GetString(c, "database.user"))
+
+	// The section partly overridden by the profiles keeps the rest.
+	type database struct {
+		Addr string
+		Port int
+		User string
+	}
+	assert.Equal(t, database{Addr: "local", Port: 3307, User: "root"}, config.Value[database](c, "database"))
+
+	// The missing profile is skipped.
+	c, err = config.NewProfile(target, "staging")
+	assert.Nil(t, err, "Must be successful.")
+	assert.Equal(t, "local", config.GetString(c, "database.addr"))
+	assert.Equal(t, 3306, config.GetInt(c, "database.port"))
+	assert.Equal(t, database{Addr: "local", Port: 3306, User: "root"}, config.Value[database](c, "database"))
+
+	// The empty optional layers don't hide the base.
+	assert.Nil(t, os.Remove(filepath.Join(dir, "app.local.yaml")))
+	c, err = config.NewProfile(target, "staging")
+	assert.Nil(t, err, "Must be successful.")
+	assert.Equal(t, map[string]interface{}{
+		"database": map[string]interface{}{"addr": "base", "port": 3306, "user": "root"},
+	}, config.Get(c, ""))
+
+	// The base is required.
+	_, err = config.NewProfile(config.T().WithScheme(localfile.Scheme()).
+		WithPath(filepath.Join(dir, "missing.yaml")), "prod")
+	assert.NotNil(t, err)
+}
+
+func TestActiveProfile(t *testing.T) {
+	os.Setenv(config.ENV_PROFILE, "staging")
+	defer os.Unsetenv(config.ENV_PROFILE)
+	assert.Equal(t, "staging", config.ActiveProfile())
+
+	assert.Equal(t, "conf/app.prod.yaml", config.ProfilePath("conf/app.yaml", "prod"))
+}
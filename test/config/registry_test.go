@@ -0,0 +1,127 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource"
+	"github.com/k8s-practice/octopus/config/datasource/localfile"
+	"github.com/k8s-practice/octopus/config/datasource/memory"
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/k8s-practice/octopus/config/parser/dotenvparser"
+	"github.com/k8s-practice/octopus/config/parser/hclparser"
+	"github.com/k8s-practice/octopus/config/parser/iniparser"
+	"github.com/k8s-practice/octopus/config/parser/json5parser"
+	"github.com/k8s-practice/octopus/config/parser/jsonparser"
+	"github.com/k8s-practice/octopus/config/parser/propertiesparser"
+	"github.com/k8s-practice/octopus/config/parser/tomlparser"
+	"github.com/k8s-practice/octopus/config/parser/yamlparser"
+	"github.com/stretchr/testify/assert"
+)
+
+// upperParser ignores the data and sets "database.addr" to "UPPER".
+type upperParser struct{}
+
+func (p *upperParser) Parse(data []byte, v interface{}) error {
+	return parser.Assign(map[string]interface{}{"database": map[string]interface{}{"addr": "UPPER"}}, v)
+}
+
+type upperBuilder struct{}
+
+func (b *upperBuilder) Build() parser.Parser { return &upperParser{} }
+func (b *upperBuilder) Format() []string     { return []string{tomlparser.Format()} }
+
+func TestParserRegistry(t *testing.T) {
+	r := parser.NewRegistry()
+	r.Register(&upperBuilder{})
+	r.Register(&upperBuilder{})
+	assert.Equal(t, []string{"toml"}, r.Formats())
+
+	c, err := config.New(
+		config.T().WithScheme(localfile.Scheme()).WithPath("./p1.toml").WithFormat(tomlparser.Format()),
+		config.WithParsers(r),
+	)
+	assert.Nil(t, err, "Must be successful.")
+	assert.Equal(t, "UPPER", config.GetString(c, "database.addr"))
+
+	// The default registry is untouched.
+	c = newConfig(t, fileTarget("./p1.toml", tomlparser.Format()))
+	assert.NotEqual(t, "UPPER", config.GetString(c, "database.addr"))
+
+	r.Unregister(tomlparser.Format())
+	assert.Empty(t, r.Formats())
+	_, err = config.New(
+		config.T().WithScheme(localfile.Scheme()).WithPath("./p1.toml").WithFormat(tomlparser.Format()),
+		config.WithParsers(r),
+	)
+	assert.NotNil(t, err)
+}
+
+type schemeBuilder struct {
+	scheme string
+}
+
+func (b *schemeBuilder) Build(t datasource.Target) (datasource.DataSource, error) {
+	return nil, fmt.Errorf("build %s", b.scheme)
+}
+
+func (b *schemeBuilder) Scheme() string { return b.scheme }
+
+func TestDatasourceRegistry(t *testing.T) {
+	r := datasource.NewRegistry()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r.Register(&schemeBuilder{scheme: fmt.Sprintf("s%d", i%5)})
+		}(i)
+	}
+	wg.Wait()
+
+	_, err := config.New(config.T().WithScheme("s1"), config.WithDatasources(r))
+	assert.EqualError(t, err, "build s1")
+
+	// The default registry doesn't know the scheme.
+	_, err = config.New(config.T().WithScheme("s1"))
+	assert.EqualError(t, err, "Unknown data source scheme [s1].")
+
+	r.Unregister("s1")
+	_, err = r.Build(config.T().WithScheme("s1"))
+	assert.EqualError(t, err, "Unknown data source scheme [s1].")
+}
+
+func TestExplicitRegistry(t *testing.T) {
+	datasources := datasource.NewRegistry()
+	datasources.Register(localfile.NewBuilder())
+	datasources.Register(memory.NewBuilder())
+
+	parsers := parser.NewRegistry()
+	for _, b := range []parser.Builder{
+		dotenvparser.NewBuilder(), hclparser.NewBuilder(), iniparser.NewBuilder(),
+		json5parser.NewBuilder(), jsonparser.NewBuilder(), propertiesparser.NewBuilder(),
+		tomlparser.NewBuilder(), yamlparser.NewBuilder(),
+	} {
+		parsers.Register(b)
+	}
+	assert.Equal(t, []string{"dotenv", "env", "hcl", "ini", "json", "json5", "jsonc", "properties", "props", "toml", "yaml", "yml"}, parsers.Formats())
+
+	for _, file := range []string{"./p1.toml", "./p2.yaml", "./p3.json", "./p4.hcl", "./p5.ini", "./p6.env", "./p7.properties"} {
+		_, err := config.New(
+			config.T().WithScheme(localfile.Scheme()).WithPath(file),
+			config.WithDatasources(datasources),
+			config.WithParsers(parsers),
+		)
+		assert.Nil(t, err, file)
+	}
+
+	c, err := config.New(
+		config.T().WithScheme(memory.Scheme()).WithValue(memory.KEY_DATA, map[string]interface{}{"a": 1}),
+		config.WithDatasources(datasources),
+	)
+	assert.Nil(t, err, "Must be successful.")
+	assert.Equal(t, 1, config.GetInt(c, "a"))
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource"
+	"github.com/k8s-practice/octopus/config/datasource/localfile"
+	"github.com/k8s-practice/octopus/config/parser/jsonparser"
+	"github.com/k8s-practice/octopus/config/parser/tomlparser"
+	"github.com/k8s-practice/octopus/config/parser/yamlparser"
+	"github.com/stretchr/testify/assert"
+)
+
+type databaseSchema struct {
+	Database struct {
+		Type string `schema:"required"`
+		Addr string `schema:"required"`
+		Port int    `schema:"required"`
+		Info struct {
+			Type string
+			Addr string
+			Time time.Time
+		}
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	s := config.SchemaOf(&databaseSchema{}).Strict()
+
+	p1 := config.T().WithScheme(localfile.Scheme()).
+		WithPath("./p1.toml").
+		WithFormat(tomlparser.Format())
+	c1, err := config.New(p1, config.WithLayerSchema(s))
+	assert.Nil(t, err, "Must be successful.")
+
+	// p1 lacks database.port, it's provided by lower layers.
+	assert.NotNil(t, s.Validate(c1))
+	_, err = config.New(p1, config.WithSchema(s))
+	assert.IsType(t, &config.SchemaError{}, err, "Missing key must be reported.")
+	assert.Nil(t, p1.Value(datasource.KEY_VALIDATOR), "Target must not be modified.")
+
+	c2, err := config.New(
+		config.T().WithScheme(localfile.Scheme()).
+			WithPath("./p2.yaml").
+			WithFormat(yamlparser.Format()),
+		config.WithLayerSchema(s),
+	)
+	assert.Nil(t, err, "Must be successful.")
+
+	c3, err := config.New(
+		config.T().WithScheme(localfile.Scheme()).
+			WithPath("./p3.json").
+			WithFormat(jsonparser.Format()),
+		config.WithLayerSchema(s),
+	)
+	assert.Nil(t, err, "Must be successful.")
+
+	assert.Nil(t, s.Validate(config.MultiConfig(c1, c2, c3)))
+	assert.Nil(t, config.Reload(config.MultiConfig(c1, c2, c3)))
+	assert.NotNil(t, config.Reload(config.MultiConfig(c1)), "Lower layers provide database.port.")
+}
+
+func TestSchemaRejectReload(t *testing.T) {
+	s := config.NewSchema().
+		Key("database.addr", config.KindString, true).
+		Key("database.port", config.KindInt, true).
+		Strict()
+
+	dir, err := os.MkdirTemp("", "octopus")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "p.yaml")
+	assert.Nil(t, os.WriteFile(file, []byte("database:\n  adr: 172.168.0.2\n"), 0644))
+
+	target := config.T().WithScheme(localfile.Scheme()).
+		WithPath(file).
+		WithFormat(yamlparser.Format())
+	_, err = config.New(target, config.WithSchema(s))
+	assert.IsType(t, &config.SchemaError{}, err, "Typo must be reported.")
+
+	assert.Nil(t, os.WriteFile(file, []byte("database:\n  addr: 172.168.0.2\n  port: 3307\n"), 0644))
+	c, err := config.New(target, config.WithSchema(s))
+	assert.Nil(t, err, "Must be successful.")
+
+	assert.Nil(t, os.WriteFile(file, []byte("database:\n  addr: 172.168.0.2\n  port: abc\n"), 0644))
+	assert.NotNil(t, config.Reload(c), "Reload must be rejected.")
+	assert.Equal(t, 3307, config.GetInt(c, "database.port"))
+}
+
+func TestSchemaProfile(t *testing.T) {
+	s := config.NewSchema().
+		Key("database.addr", config.KindString, true).
+		Key("database.port", config.KindInt, true)
+
+	dir, err := os.MkdirTemp("", "octopus")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "app.yaml")
+	assert.Nil(t, os.WriteFile(file, []byte("database:\n  addr: base\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.prod.yaml"), []byte("database:\n  port: 3307\n"), 0644))
+
+	target := config.T().WithScheme(localfile.Scheme()).WithPath(file)
+	c, err := config.NewProfile(target, "prod", config.WithSchema(s))
+	assert.Nil(t, err, "The layers are validated together.")
+	assert.Equal(t, 3307, config.GetInt(c, "database.port"))
+
+	// The reload breaking the combined schema is rejected, all layers keep
+	// their data.
+	assert.Nil(t, os.WriteFile(file, []byte("database:\n  addr: changed\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.prod.yaml"), []byte("database:\n  user: root\n"), 0644))
+	assert.IsType(t, &config.SchemaError{}, config.Reload(c), "Missing key must be reported.")
+	assert.Equal(t, "base", config.GetString(c, "database.addr"))
+	assert.Equal(t, 3307, config.GetInt(c, "database.port"))
+	assert.Nil(t, config.Get(c, "database.user"))
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "app.prod.yaml"), []byte("database:\n  port: 3308\n"), 0644))
+	assert.Nil(t, config.Reload(c))
+	assert.Equal(t, "changed", config.GetString(c, "database.addr"))
+	assert.Equal(t, 3308, config.GetInt(c, "database.port"))
+
+	_, err = config.NewProfile(target, "", config.WithSchema(s))
+	assert.IsType(t, &config.SchemaError{}, err, "Missing key must be reported.")
+}
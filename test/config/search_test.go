@@ -0,0 +1,107 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource/localfile"
+	"github.com/k8s-practice/octopus/config/parser/jsonparser"
+	"github.com/k8s-practice/octopus/internal/configsearch"
+	"github.com/stretchr/testify/assert"
+)
+
+const clustersJSON = `{
+    "clusters": [
+        {"name": "bj", "addr": "10.0.0.1:80", "replicas": [{"addr": "10.0.1.1:80"}]},
+        {"name": "sh", "addr": "10.0.0.2:80", "replicas": [{"addr": "10.0.2.1:80"}, {"addr": "10.0.2.2:80"}]}
+    ]
+}`
+
+func TestSearchWildcard(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "clusters.json")
+	assert.Nil(t, os.WriteFile(file, []byte(clustersJSON), 0644))
+
+	c, err := config.New(
+		config.T().WithScheme(localfile.Scheme()).
+			WithPath(file).
+			WithFormat(jsonparser.Format()),
+	)
+	assert.Nil(t, err, "Must be successful.")
+
+	assert.Equal(t, "10.0.0.2:80", config.GetString(c, "clusters.1.addr"))
+	assert.Equal(t, []string{"10.0.0.1:80", "10.0.0.2:80"},
+		config.GetStringSlice(c, "clusters.*.addr"))
+	assert.Equal(t, []string{"10.0.1.1:80", "10.0.2.1:80", "10.0.2.2:80"},
+		config.GetStringSlice(c, "clusters.*.replicas.*.addr"))
+	assert.Nil(t, config.Get(c, "clusters.*.port"))
+}
+
+func TestSetValueInMap(t *testing.T) {
+	m := map[string]interface{}{
+		"clusters": []interface{}{
+			map[string]interface{}{"addr": "10.0.0.1:80"},
+			map[string]interface{}{"addr": "10.0.0.2:80"},
+		},
+		"port": 80,
+	}
+
+	assert.Nil(t, configsearch.SetValueInMap(m, []string{"clusters", "0", "addr"}, "10.0.0.3:80"))
+	assert.Nil(t, configsearch.SetValueInMap(m, []string{"clusters", "2", "addr"}, "10.0.0.4:80"))
+	assert.Nil(t, configsearch.SetValueInMap(m, []string{"clusters", "*", "weight"}, 10))
+	assert.NotNil(t, configsearch.SetValueInMap(m, []string{"clusters", "4", "addr"}, "10.0.0.5:80"))
+	assert.Nil(t, configsearch.SetValueInMap(m, []string{"port", "http"}, 80))
+
+	assert.Equal(t, []interface{}{"10.0.0.3:80", "10.0.0.2:80", "10.0.0.4:80"},
+		configsearch.SearchPathInMap(m, []string{"clusters", "*", "addr"}))
+	assert.Equal(t, []interface{}{10, 10, 10},
+		configsearch.SearchPathInMap(m, []string{"clusters", "*", "weight"}))
+	assert.Equal(t, 80, configsearch.SearchPathInMap(m, []string{"port", "http"}))
+}
+
+func TestMerge(t *testing.T) {
+	dst := map[string]interface{}{
+		"database": map[string]interface{}{"addr": "base", "port": 3306},
+		"tags":     []interface{}{"a"},
+	}
+	src := map[string]interface{}{
+		"database": map[string]interface{}{"addr": "local", "user": nil},
+		"tags":     []interface{}{"b"},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"database": map[string]interface{}{"addr": "local", "port": 3306, "user": nil},
+		"tags":     []interface{}{"b"},
+	}, configsearch.Merge(dst, src))
+	assert.Equal(t, "x", configsearch.Merge(dst, "x"))
+	assert.Equal(t, "x", configsearch.Merge("x", nil))
+}
+
+func TestFields(t *testing.T) {
+	type Base struct {
+		ID int
+	}
+	type Inner struct {
+		Addr string
+	}
+	type S struct {
+		Base
+		Inner   `mapstructure:",squash"`
+		Name    string `mapstructure:"full_name"`
+		Ignored string `mapstructure:"-"`
+		Nested  Inner
+		private int
+	}
+
+	var keys []string
+	for _, f := range configsearch.Fields(reflect.TypeOf(S{})) {
+		if f.Squash {
+			keys = append(keys, "+"+f.Key)
+		} else {
+			keys = append(keys, f.Key)
+		}
+	}
+	assert.Equal(t, []string{"+base", "+inner", "full_name", "nested"}, keys)
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource/localfile"
+	"github.com/k8s-practice/octopus/config/parser/yamlparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	top := newConfig(t, memoryTarget(map[string]interface{}{
+		"database": map[string]interface{}{"addr": "top"},
+	}))
+	bottom := newConfig(t, memoryTarget(map[string]interface{}{
+		"database": map[string]interface{}{"addr": "bottom", "port": 3306},
+	}))
+
+	mc := config.MultiConfig(top, bottom)
+	s := config.Snapshot(mc)
+	assert.Equal(t, "top", config.GetString(s, "database.addr"))
+	assert.Equal(t, 3306, config.GetInt(s, "database.port"))
+
+	// The MultiConfig merges the sections like the snapshot.
+	assert.Equal(t, config.Get(s, "database"), config.Get(mc, "database"))
+	assert.Equal(t, config.Get(s, ""), config.Get(mc, ""))
+	assert.Equal(t, config.Tree(mc), config.Get(mc, ""))
+
+	assert.Nil(t, config.Set(top, "database.addr", "changed"))
+	assert.Nil(t, config.Set(bottom, "database.port", 3307))
+	assert.Equal(t, "top", config.GetString(s, "database.addr"))
+	assert.Equal(t, 3306, config.GetInt(s, "database.port"))
+
+	assert.Equal(t, config.ErrReadOnly, config.Set(s, "database.addr", "x"))
+}
+
+func TestSnapshotGetCopy(t *testing.T) {
+	c := newConfig(t, memoryTarget(map[string]interface{}{
+		"database": map[string]interface{}{"addr": "a"},
+		"servers":  []interface{}{"h1"},
+	}))
+	s := config.Snapshot(c)
+
+	for _, c := range []config.Config{c, s} {
+		db := config.Get(c, "database").(map[string]interface{})
+		db["addr"] = "changed"
+		config.Get(c, "servers").([]interface{})[0] = "changed"
+		config.Get(c, "").(map[string]interface{})["database"] = nil
+
+		assert.Equal(t, "a", config.GetString(c, "database.addr"))
+		assert.Equal(t, []interface{}{"h1"}, config.Get(c, "servers"))
+	}
+}
+
+func TestRollback(t *testing.T) {
+	dir, err := os.MkdirTemp("", "octopus")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte("database:\n  addr: a\n"), 0644))
+
+	c, err := config.New(
+		config.T().WithScheme(localfile.Scheme()).WithPath(path).WithFormat(yamlparser.Format()),
+		config.WithHistory(2),
+	)
+	assert.Nil(t, err, "Must be successful.")
+	good := config.Versions(c)[0].ID
+
+	assert.Nil(t, os.WriteFile(path, []byte("database:\n  addr: b\n"), 0644))
+	assert.Nil(t, config.Reload(c))
+	assert.Equal(t, "b", config.GetString(c, "database.addr"))
+	assert.Equal(t, 2, len(config.Versions(c)))
+
+	assert.Nil(t, config.Rollback(c, good))
+	assert.Equal(t, "a", config.GetString(c, "database.addr"))
+	assert.Equal(t, 2, len(config.Versions(c)))
+
+	// The file is untouched.
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "database:\n  addr: b\n", string(data))
+
+	// The oldest version is dropped.
+	assert.NotNil(t, config.Rollback(c, good))
+	assert.Equal(t, config.ErrNoHistory, config.Rollback(config.MultiConfig(c), good))
+}
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource"
+	"github.com/k8s-practice/octopus/config/datasource/localfile"
+	"github.com/k8s-practice/octopus/config/parser/yamlparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptional(t *testing.T) {
+	dir, err := os.MkdirTemp("", "octopus")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.local.yaml")
+	target := func() datasource.Target {
+		return config.T().WithScheme(localfile.Scheme()).WithPath(path).WithFormat(yamlparser.Format())
+	}
+
+	_, err = config.New(target())
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	c, err := config.New(target(), config.WithOptional())
+	assert.Nil(t, err, "Must be successful.")
+	assert.Nil(t, config.Get(c, "database.addr"))
+
+	assert.Nil(t, os.WriteFile(path, []byte("database:\n  addr: a\n"), 0644))
+	assert.Nil(t, config.Reload(c))
+	assert.Equal(t, "a", config.GetString(c, "database.addr"))
+}
+
+func TestStatus(t *testing.T) {
+	dir, err := os.MkdirTemp("", "octopus")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte("database:\n  addr: a\n"), 0644))
+
+	c, err := config.New(config.T().WithScheme(localfile.Scheme()).WithPath(path).WithFormat(yamlparser.Format()))
+	assert.Nil(t, err, "Must be successful.")
+	assert.Nil(t, config.LastError(c))
+	status := config.Status(c)
+	assert.Equal(t, 1, len(status))
+	assert.True(t, status[0].Healthy())
+	assert.False(t, status[0].LastSuccess.IsZero())
+
+	// The failed reload keeps the data loaded before.
+	assert.Nil(t, os.WriteFile(path, []byte("database: [a\n"), 0644))
+	assert.NotNil(t, config.Reload(c))
+	assert.NotNil(t, config.LastError(c))
+	assert.Equal(t, 1, config.Status(c)[0].Failures)
+	assert.Equal(t, "a", config.GetString(c, "database.addr"))
+
+	mc := config.MultiConfig(c, newConfig(t, fileTarget("./p2.yaml", yamlparser.Format())))
+	assert.Equal(t, 2, len(config.Status(mc)))
+	assert.NotNil(t, config.LastError(mc))
+}
+
+type flakyBuilder struct {
+	failures int
+	builds   int
+}
+
+func (b *flakyBuilder) Build(t datasource.Target) (datasource.DataSource, error) {
+	b.builds++
+	if b.builds <= b.failures {
+		return nil, errors.New("unavailable")
+	}
+
+	return datasource.DefaultRegistry.Build(config.T().WithScheme(localfile.Scheme()).
+		WithPath("./p2.yaml").WithFormat(yamlparser.Format()))
+}
+
+func (b *flakyBuilder) Scheme() string { return "flaky" }
+
+func TestRetry(t *testing.T) {
+	b := &flakyBuilder{failures: 2}
+	r := datasource.NewRegistry()
+	r.Register(b)
+
+	_, err := config.New(config.T().WithScheme("flaky"), config.WithDatasources(r))
+	assert.EqualError(t, err, "unavailable")
+
+	b.builds = 0
+	c, err := config.New(config.T().WithScheme("flaky"), config.WithDatasources(r),
+		config.WithRetry(3, time.Millisecond, 0))
+	assert.Nil(t, err, "Must be successful.")
+	assert.Equal(t, 3, b.builds)
+	assert.NotNil(t, config.Get(c, "database"))
+
+	p := &datasource.RetryPolicy{Backoff: time.Second, MaxBackoff: 3 * time.Second}
+	assert.Equal(t, time.Second, p.Delay(1))
+	assert.Equal(t, 2*time.Second, p.Delay(2))
+	assert.Equal(t, 3*time.Second, p.Delay(3))
+	assert.Equal(t, 3*time.Second, p.Delay(1000))
+
+	// The delay never overflows without MaxBackoff.
+	p = &datasource.RetryPolicy{Backoff: time.Second}
+	assert.Equal(t, time.Duration(math.MaxInt64), p.Delay(100))
+	assert.Equal(t, time.Duration(math.MaxInt64), p.Delay(math.MaxInt32))
+}
+
+// flakySource fails to load failures times.
+type flakySource struct {
+	failures int
+	loads    int
+}
+
+func (s *flakySource) Load() error {
+	s.loads++
+	if s.loads <= s.failures {
+		return errors.New("unavailable")
+	}
+
+	return nil
+}
+
+func (s *flakySource) Get(path []string) interface{} { return nil }
+
+type flakySourceBuilder struct {
+	source *flakySource
+}
+
+func (b *flakySourceBuilder) Build(t datasource.Target) (datasource.DataSource, error) {
+	return b.source, b.source.Load()
+}
+
+func (b *flakySourceBuilder) Scheme() string { return "flaky-load" }
+
+func TestRetryReload(t *testing.T) {
+	s := &flakySource{}
+	r := datasource.NewRegistry()
+	r.Register(&flakySourceBuilder{source: s})
+
+	c, err := config.New(config.T().WithScheme("flaky-load"), config.WithDatasources(r),
+		config.WithRetry(3, time.Millisecond, 0))
+	assert.Nil(t, err, "Must be successful.")
+
+	s.loads, s.failures = 0, 2
+	assert.Nil(t, config.Reload(c))
+	assert.Equal(t, 3, s.loads)
+
+	s.loads, s.failures = 0, 3
+	assert.EqualError(t, config.Reload(c), "unavailable")
+	assert.Equal(t, 3, s.loads)
+}
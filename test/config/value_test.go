@@ -0,0 +1,71 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource/localfile"
+	"github.com/k8s-practice/octopus/config/parser/tomlparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue(t *testing.T) {
+	c, err := config.New(
+		config.T().WithScheme(localfile.Scheme()).
+			WithPath("./p1.toml").
+			WithFormat(tomlparser.Format()),
+	)
+	assert.Nil(t, err, "Must be successful.")
+
+	assert.Equal(t, "172.168.0.1", config.Value[string](c, "database.info.addr"))
+	assert.Equal(t, 0, config.Value[int](c, "database.info.port"))
+	assert.Equal(t, map[string]interface{}{
+		"type": "mysql",
+		"addr": "172.168.0.1",
+		"time": "2021-03-19 14:15:16",
+	}, config.Value[map[string]interface{}](c, "database.info"))
+
+	_, err = config.ValueE[int](c, "database.info.type")
+	assert.NotNil(t, err, "mysql is not an integer.")
+
+	type info struct {
+		Type string
+		Addr string
+	}
+	assert.Equal(t, info{Type: "mysql", Addr: "172.168.0.1"},
+		config.Value[info](c, "database.info"))
+}
+
+func TestBind(t *testing.T) {
+	dir, err := os.MkdirTemp("", "octopus")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "limit.toml")
+	assert.Nil(t, os.WriteFile(file, []byte("[limit]\nqps = 100\ntimeout = \"1s\"\n"), 0644))
+
+	c, err := config.New(
+		config.T().WithScheme(localfile.Scheme()).
+			WithPath(file).
+			WithFormat(tomlparser.Format()),
+	)
+	assert.Nil(t, err, "Must be successful.")
+
+	qps := config.Bind[int](c, "limit.qps")
+	timeout := config.Bind[time.Duration](c, "limit.timeout")
+	assert.Equal(t, 100, qps.Load())
+	assert.Equal(t, time.Second, timeout.Load())
+
+	assert.Nil(t, os.WriteFile(file, []byte("[limit]\nqps = 200\ntimeout = \"2s\"\n"), 0644))
+	assert.Nil(t, config.Reload(c))
+	assert.Equal(t, 200, qps.Load())
+	assert.Equal(t, 2*time.Second, timeout.Load())
+
+	// A failed reload keeps the old values.
+	assert.Nil(t, os.WriteFile(file, []byte("[limit\n"), 0644))
+	assert.NotNil(t, config.Reload(c))
+	assert.Equal(t, 200, qps.Load())
+}
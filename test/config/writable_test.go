@@ -0,0 +1,123 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/k8s-practice/octopus/config"
+	"github.com/k8s-practice/octopus/config/datasource/localfile"
+	"github.com/k8s-practice/octopus/config/parser/jsonparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAndPersist(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "p3.json")
+	data, err := os.ReadFile("./p3.json")
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(file, data, 0600))
+
+	target := config.T().WithScheme(localfile.Scheme()).
+		WithPath(file).
+		WithFormat(jsonparser.Format())
+	c, err := config.New(target)
+	assert.Nil(t, err, "Must be successful.")
+
+	port := config.Bind[int](c, "database.port")
+	assert.Nil(t, config.Set(c, "database.port", 3309))
+	assert.Nil(t, config.Set(c, "database.replicas.0", "172.168.0.4"))
+	assert.Nil(t, config.Delete(c, "database.type"))
+	assert.Equal(t, 3309, config.GetInt(c, "database.port"))
+	assert.Equal(t, 3309, port.Load())
+	assert.Nil(t, config.Get(c, "database.type"))
+
+	// The file is untouched before Persist.
+	c2, err := config.New(target)
+	assert.Nil(t, err, "Must be successful.")
+	assert.Equal(t, 3308, config.GetInt(c2, "database.port"))
+
+	assert.Nil(t, config.Persist(c))
+	assert.Nil(t, config.Reload(c2))
+	assert.Equal(t, 3309, config.GetInt(c2, "database.port"))
+	assert.Equal(t, "172.168.0.4", config.GetString(c2, "database.replicas.0"))
+	assert.Nil(t, config.Get(c2, "database.type"))
+
+	fi, err := os.Stat(file)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+}
+
+func TestMultiConfigSet(t *testing.T) {
+	dir := t.TempDir()
+	newConfig := func(name, data string) config.Config {
+		file := filepath.Join(dir, name)
+		assert.Nil(t, os.WriteFile(file, []byte(data), 0644))
+		c, err := config.New(config.T().WithScheme(localfile.Scheme()).
+			WithPath(file).
+			WithFormat(jsonparser.Format()))
+		assert.Nil(t, err, "Must be successful.")
+		return c
+	}
+
+	c1 := newConfig("c1.json", `{"addr": "172.168.0.1"}`)
+	c2 := newConfig("c2.json", `{"addr": "172.168.0.2", "port": 3307}`)
+	c := config.MultiConfig(c1, c2)
+
+	assert.Nil(t, config.Set(c, "port", 3308))
+	assert.Equal(t, 3308, config.GetInt(c1, "port"))
+	assert.Equal(t, 3307, config.GetInt(c2, "port"))
+
+	assert.Nil(t, config.Delete(c, "addr"))
+	assert.Nil(t, config.Get(c, "addr"))
+
+	assert.Equal(t, config.ErrReadOnly, config.Set(config.MultiConfig(), "addr", ""))
+}
+
+func TestReloadWhilePersist(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		file := filepath.Join(dir, fmt.Sprintf("app%d.conf", i))
+		c, err := config.New(config.T().WithScheme(localfile.Scheme()).WithPath(file), config.WithOptional())
+		assert.Nil(t, err, "Must be successful.")
+
+		// The format is detected from the content once the file appears.
+		assert.Nil(t, os.WriteFile(file, []byte(`{"database": {"port": 3308}}`), 0644))
+
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			<-start
+			config.Reload(c)
+		}()
+		go func() {
+			defer wg.Done()
+			<-start
+			config.Persist(c)
+		}()
+		close(start)
+		wg.Wait()
+
+		assert.Nil(t, config.Reload(c))
+		assert.Nil(t, config.Persist(c))
+		assert.Equal(t, 3308, config.GetInt(c, "database.port"))
+	}
+}
+
+func TestPersistMissingOptional(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.yaml")
+	c, err := config.New(config.T().WithScheme(localfile.Scheme()).WithPath(file), config.WithOptional())
+	assert.Nil(t, err, "Must be successful.")
+
+	assert.Nil(t, config.Set(c, "database.port", 3308))
+	assert.Nil(t, config.Persist(c))
+
+	data, err := os.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, "database:\n    port: 3308\n", string(data))
+	assert.Nil(t, config.Reload(c))
+	assert.Equal(t, 3308, config.GetInt(c, "database.port"))
+}
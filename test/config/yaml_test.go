@@ -0,0 +1,76 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/k8s-practice/octopus/config/parser"
+	"github.com/k8s-practice/octopus/config/parser/yamlparser"
+	"github.com/mitchellh/mapstructure"
+	"github.com/stretchr/testify/assert"
+)
+
+const anchorsYAML = `
+defaults: &defaults
+  type: mysql
+  port: 3306
+  pool:
+    size: 10
+database:
+  <<: *defaults
+  addr: 172.168.0.2
+  port: 3307
+---
+database:
+  pool:
+    idle: 2
+`
+
+func TestYAMLNormalize(t *testing.T) {
+	m := make(map[string]interface{})
+	assert.Nil(t, parser.Parse(yamlparser.Format(), []byte(anchorsYAML), &m))
+
+	assert.Equal(t, map[string]interface{}{
+		"type": "mysql",
+		"addr": "172.168.0.2",
+		"port": 3307,
+		"pool": map[string]interface{}{
+			"size": 10,
+			"idle": 2,
+		},
+	}, m["database"])
+
+	var db struct {
+		Type string
+		Port int
+		Pool struct {
+			Size int
+			Idle int
+		}
+	}
+	assert.Nil(t, mapstructure.Decode(m["database"], &db))
+	assert.Equal(t, 10, db.Pool.Size)
+	assert.Equal(t, 2, db.Pool.Idle)
+}
+
+func TestYAMLError(t *testing.T) {
+	m := make(map[string]interface{})
+	var pe *parser.ParseError
+
+	err := parser.Parse(yamlparser.Format(), []byte("database:\n  addr: a\n  addr: b\n"), &m)
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, 3, pe.Line)
+	assert.Equal(t, 3, pe.Column)
+	assert.EqualError(t, pe.Err, `mapping key "addr" already defined at line 2, column 3`)
+
+	err = parser.Parse(yamlparser.Format(), []byte("database:\n  <<: 1\n"), &m)
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, 2, pe.Line)
+	assert.Equal(t, 7, pe.Column)
+	assert.EqualError(t, pe.Err, "merge value must be a mapping or a sequence of mappings")
+
+	err = parser.Parse(yamlparser.Format(), []byte("database:\n  addr: [a\n"), &m)
+	assert.True(t, errors.As(err, &pe))
+	assert.Equal(t, "yaml", pe.Format)
+	assert.Greater(t, pe.Line, 0)
+}
@@ -1,6 +1,7 @@
 package cast
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
@@ -85,6 +86,7 @@ func TestToUint64E(t *testing.T) {
 		{false, 0, false},
 		{"8", 8, false},
 		{nil, 0, false},
+		{json.Number("18446744073709551615"), 18446744073709551615, false},
 		// errors
 		{int(-8), 0, true},
 		{int8(-8), 0, true},
@@ -342,6 +344,7 @@ func TestToInt64E(t *testing.T) {
 		{false, 0, false},
 		{"8", 8, false},
 		{nil, 0, false},
+		{json.Number("1234567890123456789"), 1234567890123456789, false},
 		// errors
 		{"test", 0, true},
 		{testing.T{}, 0, true},
@@ -521,6 +524,7 @@ func TestToFloat64E(t *testing.T) {
 		{"8", 8, false},
 		{true, 1, false},
 		{false, 0, false},
+		{json.Number("8.31"), 8.31, false},
 		// errors
 		{"test", 0, true},
 		{testing.T{}, 0, true},
@@ -91,6 +91,8 @@ func ToFloat64E(i interface{}) (float64, error) {
 	i = indirect(i)
 
 	switch s := i.(type) {
+	case json.Number:
+		return ToFloat64E(string(s))
 	case float64:
 		return s, nil
 	case float32:
@@ -136,6 +138,8 @@ func ToFloat32E(i interface{}) (float32, error) {
 	i = indirect(i)
 
 	switch s := i.(type) {
+	case json.Number:
+		return ToFloat32E(string(s))
 	case float64:
 		return float32(s), nil
 	case float32:
@@ -181,6 +185,8 @@ func ToInt64E(i interface{}) (int64, error) {
 	i = indirect(i)
 
 	switch s := i.(type) {
+	case json.Number:
+		return ToInt64E(string(s))
 	case int:
 		return int64(s), nil
 	case int64:
@@ -228,6 +234,8 @@ func ToInt32E(i interface{}) (int32, error) {
 	i = indirect(i)
 
 	switch s := i.(type) {
+	case json.Number:
+		return ToInt32E(string(s))
 	case int:
 		return int32(s), nil
 	case int64:
@@ -275,6 +283,8 @@ func ToInt16E(i interface{}) (int16, error) {
 	i = indirect(i)
 
 	switch s := i.(type) {
+	case json.Number:
+		return ToInt16E(string(s))
 	case int:
 		return int16(s), nil
 	case int64:
@@ -322,6 +332,8 @@ func ToInt8E(i interface{}) (int8, error) {
 	i = indirect(i)
 
 	switch s := i.(type) {
+	case json.Number:
+		return ToInt8E(string(s))
 	case int:
 		return int8(s), nil
 	case int64:
@@ -369,6 +381,8 @@ func ToIntE(i interface{}) (int, error) {
 	i = indirect(i)
 
 	switch s := i.(type) {
+	case json.Number:
+		return ToIntE(string(s))
 	case int:
 		return s, nil
 	case int64:
@@ -416,6 +430,8 @@ func ToUintE(i interface{}) (uint, error) {
 	i = indirect(i)
 
 	switch s := i.(type) {
+	case json.Number:
+		return ToUintE(string(s))
 	case string:
 		v, err := strconv.ParseUint(s, 0, 0)
 		if err == nil {
@@ -484,6 +500,8 @@ func ToUint64E(i interface{}) (uint64, error) {
 	i = indirect(i)
 
 	switch s := i.(type) {
+	case json.Number:
+		return ToUint64E(string(s))
 	case string:
 		v, err := strconv.ParseUint(s, 0, 64)
 		if err == nil {
@@ -552,6 +570,8 @@ func ToUint32E(i interface{}) (uint32, error) {
 	i = indirect(i)
 
 	switch s := i.(type) {
+	case json.Number:
+		return ToUint32E(string(s))
 	case string:
 		v, err := strconv.ParseUint(s, 0, 32)
 		if err == nil {
@@ -620,6 +640,8 @@ func ToUint16E(i interface{}) (uint16, error) {
 	i = indirect(i)
 
 	switch s := i.(type) {
+	case json.Number:
+		return ToUint16E(string(s))
 	case string:
 		v, err := strconv.ParseUint(s, 0, 16)
 		if err == nil {
@@ -688,6 +710,8 @@ func ToUint8E(i interface{}) (uint8, error) {
 	i = indirect(i)
 
 	switch s := i.(type) {
+	case json.Number:
+		return ToUint8E(string(s))
 	case string:
 		v, err := strconv.ParseUint(s, 0, 8)
 		if err == nil {
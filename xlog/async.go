@@ -0,0 +1,250 @@
+package xlog
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// DEFAULT_ASYNC_SIZE is the number of entries buffered by an async Logger
+// by default.
+const DEFAULT_ASYNC_SIZE = 8192
+
+// OverflowPolicy decides what to do when the buffer of an async Logger
+// is full.
+type OverflowPolicy int
+
+const (
+	// Block blocks the caller until the buffer has room.
+	Block OverflowPolicy = iota
+
+	// Drop drops the entry, counted by Logger.Dropped.
+	Drop
+
+	// DropDebug drops the entries of DebugLevel, and blocks for the others.
+	DropDebug
+)
+
+// AsyncConfig configures the async mode of Logger.
+type AsyncConfig struct {
+	// Size is the capacity of the ring buffer in entries, DEFAULT_ASYNC_SIZE
+	// if it's not positive.
+	Size int
+
+	// Overflow is the policy when the buffer is full.
+	Overflow OverflowPolicy
+}
+
+// Async makes l write asynchronously: the entries are encoded by the caller
+// and buffered, a background goroutine writes them to the writer of l in
+// batches. The entries of PanicLevel and FatalLevel are flushed before the
+// call returns. Call Close on shutdown to flush the buffer, the Backend of
+// l is still written synchronously.
+func (l *Logger) Async(cfg AsyncConfig) {
+	if cfg.Size <= 0 {
+		cfg.Size = DEFAULT_ASYNC_SIZE
+	}
+
+	a := &asyncWriter{
+		out:    l.out,
+		policy: cfg.Overflow,
+		ring:   make([][]byte, cfg.Size),
+		done:   make(chan struct{}),
+	}
+	a.notEmpty = sync.NewCond(&a.mu)
+	a.notFull = sync.NewCond(&a.mu)
+	a.flushed = sync.NewCond(&a.mu)
+	go a.run()
+
+	if old := l.swapAsync(a); old != nil {
+		old.close()
+	}
+}
+
+// Sync flushes the buffered entries if l is async, and syncs the writer of
+// l if it supports, e.g. *os.File and *FileWriter.
+func (l *Logger) Sync() error {
+	if a := l.asyncWriter(); a != nil {
+		return a.sync()
+	}
+
+	return syncOut(l.out)
+}
+
+// Close flushes the buffered entries and stops the background goroutine,
+// then l writes synchronously. It's a no-op if l is not async.
+func (l *Logger) Close() error {
+	if a := l.swapAsync(nil); a != nil {
+		return a.close()
+	}
+
+	return nil
+}
+
+// Dropped returns the number of entries dropped by the overflow policy of
+// the current async mode.
+func (l *Logger) Dropped() uint64 {
+	if a := l.asyncWriter(); a != nil {
+		return atomic.LoadUint64(&a.dropped)
+	}
+
+	return 0
+}
+
+// Sync flushes and syncs the standard logger.
+func Sync() error {
+	return std.Sync()
+}
+
+func (l *Logger) asyncWriter() *asyncWriter {
+	a, _ := l.async.Load().(*asyncWriter)
+	return a
+}
+
+// swapAsync replaces the asyncWriter of l by a, returns the old one.
+func (l *Logger) swapAsync(a *asyncWriter) *asyncWriter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	old := l.asyncWriter()
+	l.async.Store(a)
+
+	return old
+}
+
+func syncOut(out io.Writer) error {
+	if s, ok := out.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+
+	return nil
+}
+
+// asyncWriter buffers the encoded entries in a ring, and writes them to out
+// in the background.
+type asyncWriter struct {
+	// dropped is the first field to be 64-bit aligned for atomic.
+	dropped uint64
+
+	out    io.Writer
+	policy OverflowPolicy
+
+	mu sync.Mutex
+	// notEmpty, notFull and flushed wait for mu.
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	flushed  *sync.Cond
+	ring     [][]byte
+	head, n  int
+	// enqueued and written count the entries to compare in sync.
+	enqueued uint64
+	written  uint64
+	// err is the first error of writing since the last sync.
+	err    error
+	closed bool
+	done   chan struct{}
+}
+
+// write copies p into the ring, or writes p synchronously if a is closed.
+func (a *asyncWriter) write(lvl Level, p []byte) error {
+	a.mu.Lock()
+	for a.n == len(a.ring) && !a.closed {
+		if a.policy == Drop || (a.policy == DropDebug && lvl == DebugLevel) {
+			a.mu.Unlock()
+			atomic.AddUint64(&a.dropped, 1)
+			return nil
+		}
+		a.notFull.Wait()
+	}
+	if a.closed {
+		a.mu.Unlock()
+		_, err := a.out.Write(p)
+		return err
+	}
+
+	a.ring[(a.head+a.n)%len(a.ring)] = append(bufPool.Get().([]byte)[:0], p...)
+	a.n++
+	a.enqueued++
+	a.notEmpty.Signal()
+	a.mu.Unlock()
+
+	return nil
+}
+
+// run writes the buffered entries in batches until a is closed and drained.
+func (a *asyncWriter) run() {
+	defer close(a.done)
+
+	var (
+		pending [][]byte
+		batch   []byte
+	)
+	for {
+		a.mu.Lock()
+		for a.n == 0 && !a.closed {
+			a.notEmpty.Wait()
+		}
+		if a.n == 0 {
+			a.mu.Unlock()
+			return
+		}
+		pending = pending[:0]
+		for i := 0; i < a.n; i++ {
+			j := (a.head + i) % len(a.ring)
+			pending = append(pending, a.ring[j])
+			a.ring[j] = nil
+		}
+		a.head, a.n = (a.head+a.n)%len(a.ring), 0
+		a.notFull.Broadcast()
+		a.mu.Unlock()
+
+		batch = batch[:0]
+		for _, buf := range pending {
+			batch = append(batch, buf...)
+			bufPool.Put(buf)
+		}
+		_, err := a.out.Write(batch)
+		if cap(batch) > 1<<20 {
+			// Releases the batch grown by bursts.
+			batch = nil
+		}
+
+		a.mu.Lock()
+		if a.err == nil {
+			a.err = err
+		}
+		a.written += uint64(len(pending))
+		a.flushed.Broadcast()
+		a.mu.Unlock()
+	}
+}
+
+// sync waits for the entries enqueued before being written, then syncs out.
+func (a *asyncWriter) sync() error {
+	a.mu.Lock()
+	target := a.enqueued
+	for a.written < target {
+		a.flushed.Wait()
+	}
+	err := a.err
+	a.err = nil
+	a.mu.Unlock()
+
+	if serr := syncOut(a.out); err == nil {
+		err = serr
+	}
+
+	return err
+}
+
+// close drains the ring and stops run.
+func (a *asyncWriter) close() error {
+	a.mu.Lock()
+	a.closed = true
+	a.notEmpty.Broadcast()
+	a.notFull.Broadcast()
+	a.mu.Unlock()
+	<-a.done
+
+	return a.sync()
+}
@@ -0,0 +1,139 @@
+package xlog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// blockingWriter blocks Write until released.
+type blockingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{entered: make(chan struct{}, 1), release: make(chan struct{})}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+	default:
+	}
+	<-w.release
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncOrder(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewStdLog(&buf, "", DebugLevel, 0)
+	l.Async(AsyncConfig{Size: 4})
+
+	var want strings.Builder
+	for i := 0; i < 100; i++ {
+		l.Infoln(i)
+		fmt.Fprintf(&want, "INFO %d\n", i)
+	}
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want.String() {
+		t.Fatalf("Expect [%s], got [%s].", want.String(), got)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Synchronous after Close.
+	l.Info("sync")
+	if got := buf.String(); !strings.HasSuffix(got, "INFO sync\n") {
+		t.Fatalf("Unexpected output [%s].", got)
+	}
+}
+
+func TestAsyncOverflow(t *testing.T) {
+	for _, c := range []struct {
+		policy  OverflowPolicy
+		dropped uint64
+		want    string
+	}{
+		{Drop, 3, "INFO 0\nINFO 1\nDEBUG 2\n"},
+		{DropDebug, 2, "INFO 0\nINFO 1\nDEBUG 2\nINFO 4\n"},
+	} {
+		w := newBlockingWriter()
+		l := NewStdLog(w, "", DebugLevel, 0)
+		l.Async(AsyncConfig{Size: 2, Overflow: c.policy})
+
+		l.Info(0)
+		// The flusher is blocked writing entry 0, the ring is empty.
+		<-w.entered
+		l.Info(1)
+		l.Debug(2)
+		// The ring is full.
+		l.Debug(3)
+		l.Debug(5)
+
+		if c.policy == Drop {
+			l.Info(4)
+			close(w.release)
+		} else {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				// Blocks if the flusher has not taken the ring yet.
+				l.Info(4)
+			}()
+			close(w.release)
+			<-done
+		}
+
+		if got := l.Dropped(); got != c.dropped {
+			t.Errorf("Expect %d dropped by policy %d, got %d.", c.dropped, c.policy, got)
+		}
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := w.String(); got != c.want {
+			t.Errorf("Expect [%s] by policy %d, got [%s].", c.want, c.policy, got)
+		}
+	}
+}
+
+func TestAsyncConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewStdLog(&buf, "", DebugLevel, 0)
+	l.Async(AsyncConfig{Size: 16})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				l.With(Int("j", j)).Info("concurrent")
+			}
+		}()
+	}
+	wg.Wait()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := strings.Count(buf.String(), "INFO concurrent j="); n != 1000 {
+		t.Fatalf("Expect 1000 entries, got %d.", n)
+	}
+}
@@ -0,0 +1,459 @@
+package xlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Encoder encodes an Entry into a line, flag are the flags of the Logger
+// deciding which of time, caller and function are encoded.
+type Encoder interface {
+	Encode(buf []byte, e *Entry, flag int32) []byte
+}
+
+// The special time formats of EncoderConfig, the others are the layouts of
+// time.Format.
+const (
+	TimeUnix      = "unix"
+	TimeUnixMilli = "unixmilli"
+	TimeUnixNano  = "unixnano"
+)
+
+// EncoderConfig configures the keys and time format of encoders. The empty
+// key drops the part.
+type EncoderConfig struct {
+	TimeKey     string
+	LevelKey    string
+	CallerKey   string
+	FunctionKey string
+	PrefixKey   string
+	MessageKey  string
+
+	// TimeFormat is the layout of time, or TimeUnix, TimeUnixMilli and
+	// TimeUnixNano. The text encoder formats the time by flags if it's
+	// empty, the others use time.RFC3339Nano.
+	TimeFormat string
+}
+
+// DefaultEncoderConfig returns the config with the keys recognized by
+// most of the log pipelines, e.g. Loki.
+func DefaultEncoderConfig() EncoderConfig {
+	return EncoderConfig{
+		TimeKey:     "time",
+		LevelKey:    "level",
+		CallerKey:   "caller",
+		FunctionKey: "func",
+		PrefixKey:   "prefix",
+		MessageKey:  "msg",
+	}
+}
+
+// NewEncoder creates the encoder by name, text, json or logfmt.
+func NewEncoder(name string, cfg EncoderConfig) (Encoder, error) {
+	switch strings.ToLower(name) {
+	case "", "text":
+		return NewTextEncoder(cfg), nil
+	case "json":
+		return NewJSONEncoder(cfg), nil
+	case "logfmt":
+		return NewLogfmtEncoder(cfg), nil
+	default:
+		return nil, fmt.Errorf("Unknown encoder [%s].", name)
+	}
+}
+
+// encoderHolder makes the encoders of different types storable in one
+// atomic.Value.
+type encoderHolder struct {
+	Encoder
+}
+
+var defaultEncoder Encoder = NewTextEncoder(EncoderConfig{})
+
+type textEncoder struct {
+	cfg EncoderConfig
+}
+
+// NewTextEncoder creates the encoder of the classic layout, only
+// cfg.TimeFormat is used:
+//
+//	prefix 2009/01/23 01:23:23.123123 INFO d.go:23 main.main() message k=v
+//
+// The prefix goes before the message if Lmsgprefix is set.
+func NewTextEncoder(cfg EncoderConfig) Encoder {
+	return &textEncoder{cfg: cfg}
+}
+
+func (enc *textEncoder) Encode(buf []byte, e *Entry, flag int32) []byte {
+	if flag&Lmsgprefix == 0 {
+		buf = append(buf, e.Prefix...)
+	}
+	if flag&(Ldate|Ltime|Lmicroseconds) != 0 {
+		t := e.Time
+		if flag&LUTC != 0 {
+			t = t.UTC()
+		}
+		if enc.cfg.TimeFormat != "" {
+			buf = appendTime(buf, t, enc.cfg.TimeFormat)
+			buf = append(buf, ' ')
+		} else {
+			buf = appendClassicTime(buf, t, flag)
+		}
+	}
+	buf = append(buf, e.Level.String()...)
+	buf = append(buf, ' ')
+	if flag&(Lshortfile|Llongfile) != 0 {
+		buf = appendCaller(buf, e, flag)
+		buf = append(buf, ' ')
+	}
+	if flag&Lfunction != 0 {
+		buf = append(buf, e.Function...)
+		buf = append(buf, "() "...)
+	}
+	if flag&Lmsgprefix != 0 {
+		buf = append(buf, e.Prefix...)
+	}
+
+	if len(e.Context) == 0 && len(e.Fields) == 0 {
+		buf = append(buf, e.Message...)
+	} else {
+		buf = append(buf, e.message()...)
+		buf = appendFields(buf, e.Context)
+		buf = appendFields(buf, e.Fields)
+	}
+	if len(buf) == 0 || buf[len(buf)-1] != '\n' {
+		buf = append(buf, '\n')
+	}
+
+	return buf
+}
+
+// appendClassicTime appends the date and time of the log package.
+func appendClassicTime(buf []byte, t time.Time, flag int32) []byte {
+	if flag&Ldate != 0 {
+		year, month, day := t.Date()
+		itoa(&buf, year, 4)
+		buf = append(buf, '/')
+		itoa(&buf, int(month), 2)
+		buf = append(buf, '/')
+		itoa(&buf, day, 2)
+		buf = append(buf, ' ')
+	}
+	if flag&(Ltime|Lmicroseconds) != 0 {
+		hour, min, sec := t.Clock()
+		itoa(&buf, hour, 2)
+		buf = append(buf, ':')
+		itoa(&buf, min, 2)
+		buf = append(buf, ':')
+		itoa(&buf, sec, 2)
+		if flag&Lmicroseconds != 0 {
+			buf = append(buf, '.')
+			itoa(&buf, t.Nanosecond()/1e3, 6)
+		}
+		buf = append(buf, ' ')
+	}
+
+	return buf
+}
+
+// appendCaller appends file:line, the file is shortened if Lshortfile is set.
+func appendCaller(buf []byte, e *Entry, flag int32) []byte {
+	file := e.File
+	if flag&Lshortfile != 0 {
+		for i := len(file) - 1; i > 0; i-- {
+			if file[i] == '/' {
+				file = file[i+1:]
+				break
+			}
+		}
+	}
+	buf = append(buf, file...)
+	buf = append(buf, ':')
+	itoa(&buf, e.Line, -1)
+
+	return buf
+}
+
+// appendTime appends t in format, see EncoderConfig.TimeFormat.
+func appendTime(buf []byte, t time.Time, format string) []byte {
+	switch format {
+	case TimeUnix:
+		return strconv.AppendInt(buf, t.Unix(), 10)
+	case TimeUnixMilli:
+		return strconv.AppendInt(buf, t.UnixNano()/int64(time.Millisecond), 10)
+	case TimeUnixNano:
+		return strconv.AppendInt(buf, t.UnixNano(), 10)
+	case "":
+		return t.AppendFormat(buf, time.RFC3339Nano)
+	default:
+		return t.AppendFormat(buf, format)
+	}
+}
+
+// levelNames are the lower case names of levels used by the structured
+// encoders.
+var levelNames = map[Level]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+	PanicLevel: "panic",
+	FatalLevel: "fatal",
+}
+
+func levelName(lvl Level) string {
+	if name, ok := levelNames[lvl]; ok {
+		return name
+	}
+
+	return lvl.String()
+}
+
+type logfmtEncoder struct {
+	cfg EncoderConfig
+}
+
+// NewLogfmtEncoder creates the encoder of logfmt, e.g.
+//
+//	time=2009-01-23T01:23:23.123123Z level=info caller=d.go:23 msg="hello world" k=v
+//
+// The levels are in lower case.
+func NewLogfmtEncoder(cfg EncoderConfig) Encoder {
+	return &logfmtEncoder{cfg: cfg}
+}
+
+func (enc *logfmtEncoder) Encode(buf []byte, e *Entry, flag int32) []byte {
+	start := len(buf)
+	key := func(k string) {
+		if len(buf) > start {
+			buf = append(buf, ' ')
+		}
+		buf = appendString(buf, k)
+		buf = append(buf, '=')
+	}
+
+	if enc.cfg.TimeKey != "" && flag&(Ldate|Ltime|Lmicroseconds) != 0 {
+		key(enc.cfg.TimeKey)
+		n := len(buf)
+		buf = quoteFrom(appendTime(buf, entryTime(e, flag), enc.cfg.TimeFormat), n)
+	}
+	if enc.cfg.LevelKey != "" {
+		key(enc.cfg.LevelKey)
+		buf = append(buf, levelName(e.Level)...)
+	}
+	if enc.cfg.CallerKey != "" && flag&(Lshortfile|Llongfile) != 0 {
+		key(enc.cfg.CallerKey)
+		n := len(buf)
+		buf = quoteFrom(appendCaller(buf, e, flag), n)
+	}
+	if enc.cfg.FunctionKey != "" && flag&Lfunction != 0 {
+		key(enc.cfg.FunctionKey)
+		buf = appendString(buf, e.Function)
+	}
+	if enc.cfg.PrefixKey != "" && e.Prefix != "" {
+		key(enc.cfg.PrefixKey)
+		buf = appendString(buf, e.Prefix)
+	}
+	if enc.cfg.MessageKey != "" {
+		key(enc.cfg.MessageKey)
+		buf = appendString(buf, e.message())
+	}
+
+	fields := len(buf)
+	buf = appendFields(buf, e.Context)
+	buf = appendFields(buf, e.Fields)
+	if fields == start && len(buf) > start {
+		// Drops the leading space of fields.
+		buf = append(buf[:start], buf[start+1:]...)
+	}
+
+	return append(buf, '\n')
+}
+
+// quoteFrom quotes buf[n:] in place if necessary, e.g. the time layouts and
+// paths containing spaces.
+func quoteFrom(buf []byte, n int) []byte {
+	if tail := buf[n:]; needsQuote(string(tail)) {
+		return strconv.AppendQuote(buf[:n], string(tail))
+	}
+
+	return buf
+}
+
+type jsonEncoder struct {
+	cfg EncoderConfig
+}
+
+// NewJSONEncoder creates the encoder of JSON lines, e.g.
+//
+//	{"time":"2009-01-23T01:23:23.123123Z","level":"info","msg":"hello","k":"v"}
+//
+// The levels are in lower case, the durations are strings like "1.5s", the
+// times of fields are formatted by cfg.TimeFormat.
+func NewJSONEncoder(cfg EncoderConfig) Encoder {
+	return &jsonEncoder{cfg: cfg}
+}
+
+func (enc *jsonEncoder) Encode(buf []byte, e *Entry, flag int32) []byte {
+	buf = append(buf, '{')
+	start := len(buf)
+	key := func(k string) {
+		if len(buf) > start {
+			buf = append(buf, ',')
+		}
+		buf = appendJSONString(buf, k)
+		buf = append(buf, ':')
+	}
+
+	if enc.cfg.TimeKey != "" && flag&(Ldate|Ltime|Lmicroseconds) != 0 {
+		key(enc.cfg.TimeKey)
+		buf = enc.appendTime(buf, entryTime(e, flag))
+	}
+	if enc.cfg.LevelKey != "" {
+		key(enc.cfg.LevelKey)
+		buf = appendJSONString(buf, levelName(e.Level))
+	}
+	if enc.cfg.CallerKey != "" && flag&(Lshortfile|Llongfile) != 0 {
+		key(enc.cfg.CallerKey)
+		buf = append(buf, '"')
+		n := len(buf)
+		buf = appendCaller(buf, e, flag)
+		if !jsonSafe(buf[n:]) {
+			caller := string(buf[n:])
+			buf = appendJSONString(buf[:n-1], caller)
+		} else {
+			buf = append(buf, '"')
+		}
+	}
+	if enc.cfg.FunctionKey != "" && flag&Lfunction != 0 {
+		key(enc.cfg.FunctionKey)
+		buf = appendJSONString(buf, e.Function)
+	}
+	if enc.cfg.PrefixKey != "" && e.Prefix != "" {
+		key(enc.cfg.PrefixKey)
+		buf = appendJSONString(buf, e.Prefix)
+	}
+	if enc.cfg.MessageKey != "" {
+		key(enc.cfg.MessageKey)
+		buf = appendJSONString(buf, e.message())
+	}
+	for _, fields := range [2][]Field{e.Context, e.Fields} {
+		for i := range fields {
+			f := &fields[i]
+			if f.Type == SkipType {
+				continue
+			}
+			key(f.Key)
+			buf = enc.appendValue(buf, f)
+		}
+	}
+
+	return append(buf, '}', '\n')
+}
+
+// appendTime appends t as a JSON string, or a number if it's unix time.
+func (enc *jsonEncoder) appendTime(buf []byte, t time.Time) []byte {
+	switch enc.cfg.TimeFormat {
+	case TimeUnix, TimeUnixMilli, TimeUnixNano:
+		return appendTime(buf, t, enc.cfg.TimeFormat)
+	}
+	buf = append(buf, '"')
+	buf = appendTime(buf, t, enc.cfg.TimeFormat)
+
+	return append(buf, '"')
+}
+
+func (enc *jsonEncoder) appendValue(buf []byte, f *Field) []byte {
+	switch f.Type {
+	case StringType:
+		return appendJSONString(buf, f.String)
+	case IntType:
+		return strconv.AppendInt(buf, f.Integer, 10)
+	case UintType:
+		return strconv.AppendUint(buf, uint64(f.Integer), 10)
+	case FloatType:
+		v := math.Float64frombits(uint64(f.Integer))
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			// JSON has no NaN and Inf.
+			return strconv.AppendQuote(buf, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		return strconv.AppendFloat(buf, v, 'g', -1, 64)
+	case BoolType:
+		return strconv.AppendBool(buf, f.Integer != 0)
+	case DurationType:
+		return appendJSONString(buf, time.Duration(f.Integer).String())
+	case TimeType:
+		return enc.appendTime(buf, f.time())
+	case ErrorType:
+		return appendJSONString(buf, f.Interface.(error).Error())
+	default:
+		if data, err := json.Marshal(f.Interface); err == nil {
+			return append(buf, data...)
+		}
+		return appendJSONString(buf, fmt.Sprint(f.Interface))
+	}
+}
+
+// entryTime returns the time of e, in UTC if LUTC is set.
+func entryTime(e *Entry, flag int32) time.Time {
+	if flag&LUTC != 0 {
+		return e.Time.UTC()
+	}
+
+	return e.Time
+}
+
+// jsonSafe reports whether b needs no escaping in JSON strings.
+func jsonSafe(b []byte) bool {
+	for _, c := range b {
+		if c < ' ' || c == '"' || c == '\\' || c >= utf8.RuneSelf {
+			return false
+		}
+	}
+
+	return true
+}
+
+const hex = "0123456789abcdef"
+
+// appendJSONString appends s as a JSON string, the invalid UTF-8 bytes are
+// replaced by U+FFFD.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				buf = append(buf, '\\', c)
+			case c == '\n':
+				buf = append(buf, '\\', 'n')
+			case c == '\r':
+				buf = append(buf, '\\', 'r')
+			case c == '\t':
+				buf = append(buf, '\\', 't')
+			case c < ' ':
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			default:
+				buf = append(buf, c)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, "\ufffd"...)
+		} else {
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+
+	return append(buf, '"')
+}
@@ -0,0 +1,121 @@
+package xlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEntry() *Entry {
+	return &Entry{
+		Time:     time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC),
+		Level:    WarnLevel,
+		Prefix:   "[app] ",
+		File:     "/src/my app/main.go",
+		Line:     42,
+		Function: "main.main",
+		Message:  "hello \"world\"\n",
+		Context:  []Field{String("module", "config")},
+		Fields:   []Field{Int("n", 1), Float64("nan", math.NaN()), Err(errors.New("boom")), Err(nil)},
+	}
+}
+
+func TestTextEncoder(t *testing.T) {
+	flag := Ldate | Lmicroseconds | Lshortfile | Lfunction | Lmsgprefix
+	got := string(NewTextEncoder(EncoderConfig{}).Encode(nil, testEntry(), flag))
+	want := `2020/01/02 03:04:05.000006 WARN main.go:42 main.main() [app] hello "world" module=config n=1 nan=NaN error=boom` + "\n"
+	if got != want {
+		t.Fatalf("Expect [%s], got [%s].", want, got)
+	}
+
+	got = string(NewTextEncoder(EncoderConfig{TimeFormat: time.RFC3339}).Encode(nil, &Entry{Time: testEntry().Time, Message: "a\nb\n"}, Ltime))
+	if want = "2020-01-02T03:04:05Z DEBUG a\nb\n"; got != want {
+		t.Fatalf("Expect [%s], got [%s].", want, got)
+	}
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	cfg := DefaultEncoderConfig()
+	cfg.FunctionKey = ""
+	cfg.TimeFormat = "2006-01-02 15:04:05"
+	got := string(NewLogfmtEncoder(cfg).Encode(nil, testEntry(), LstdFlags|Llongfile))
+	want := `time="2020-01-02 03:04:05" level=warn caller=main.go:42 prefix="[app] " msg="hello \"world\"" module=config n=1 nan=NaN error=boom` + "\n"
+	if got != want {
+		t.Fatalf("Expect [%s], got [%s].", want, got)
+	}
+
+	got = string(NewLogfmtEncoder(EncoderConfig{}).Encode(nil, testEntry(), Llongfile))
+	if want = "module=config n=1 nan=NaN error=boom\n"; got != want {
+		t.Fatalf("Expect [%s], got [%s].", want, got)
+	}
+}
+
+func TestJSONEncoder(t *testing.T) {
+	cfg := DefaultEncoderConfig()
+	cfg.MessageKey = "message"
+	cfg.TimeFormat = TimeUnixMilli
+	e := testEntry()
+	e.Fields = append(e.Fields, Any("tags", []string{"a"}), Duration("took", time.Second), String("bad", "\xff\x01"))
+	data := NewJSONEncoder(cfg).Encode(nil, e, Ldate|Llongfile|Lfunction)
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("Invalid JSON [%s]: %v.", data, err)
+	}
+	want := map[string]interface{}{
+		"time":    float64(1577934245000),
+		"level":   "warn",
+		"caller":  "/src/my app/main.go:42",
+		"func":    "main.main",
+		"prefix":  "[app] ",
+		"message": "hello \"world\"",
+		"module":  "config",
+		"n":       float64(1),
+		"nan":     "NaN",
+		"error":   "boom",
+		"tags":    []interface{}{"a"},
+		"took":    "1s",
+		"bad":     "�\x01",
+	}
+	for k, v := range want {
+		if got, _ := json.Marshal(m[k]); !bytes.Equal(got, mustMarshal(v)) {
+			t.Errorf("Expect %s [%s], got [%s].", k, mustMarshal(v), got)
+		}
+	}
+	if len(m) != len(want) {
+		t.Errorf("Expect %d keys, got %v.", len(want), m)
+	}
+}
+
+func TestSetEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewStdLog(&buf, "", DebugLevel, 0)
+	enc, err := NewEncoder("JSON", DefaultEncoderConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetEncoder(enc)
+	l.With(Int("n", 1)).Info("hi")
+	l.Infoln("plain")
+	want := `{"level":"info","msg":"hi","n":1}` + "\n" + `{"level":"info","msg":"plain"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("Expect [%s], got [%s].", want, got)
+	}
+
+	if _, err = NewEncoder("xml", EncoderConfig{}); err == nil || !strings.Contains(err.Error(), "xml") {
+		t.Fatalf("Expect error of unknown encoder, got %v.", err)
+	}
+}
+
+func mustMarshal(v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
@@ -1 +1,67 @@
 package xlog
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry is a logging event to encode.
+type Entry struct {
+	Time  time.Time
+	Level Level
+
+	// Prefix is the prefix of the Logger.
+	Prefix string
+
+	// File, Line and Function are the caller, they are "???" and 0 unless
+	// the flags of the Logger require them.
+	File     string
+	Line     int
+	Function string
+
+	Message string
+
+	// Context are the fields of the FieldLogger, Fields are of the call.
+	Context []Field
+	Fields  []Field
+
+	// buf holds Fields and text holds the encoded entry across the entries
+	// reused by entryPool.
+	buf  []Field
+	text []byte
+
+	// fromZap marks the entry written by the core of NewZapCore.
+	fromZap bool
+}
+
+// maxEntryText is the max capacity of the text kept by the Entry, the
+// larger one grown by a huge entry is released.
+const maxEntryText = 64 << 10
+
+// Backend receives the entries of a Logger, e.g. to log through another
+// logging library. The entry and its fields are reused after Write returns.
+type Backend interface {
+	Write(e *Entry) error
+}
+
+// backendHolder makes the backends of different types storable in one
+// atomic.Value, including nil.
+type backendHolder struct {
+	Backend
+}
+
+// entryPool reuses the entries, since they escape to Encoder.
+var entryPool = sync.Pool{
+	New: func() interface{} {
+		return new(Entry)
+	},
+}
+
+// message returns the message without the trailing newline.
+func (e *Entry) message() string {
+	if n := len(e.Message); n > 0 && e.Message[n-1] == '\n' {
+		return e.Message[:n-1]
+	}
+
+	return e.Message
+}
@@ -0,0 +1,211 @@
+package xlog
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldType indicates which member of Field holds the value.
+type FieldType uint8
+
+const (
+	// SkipType is the field encoding nothing, e.g. Err(nil).
+	SkipType FieldType = iota
+	StringType
+	IntType
+	UintType
+	FloatType
+	BoolType
+	DurationType
+	TimeType
+	ErrorType
+	AnyType
+)
+
+// Field is a key/value pair of the structured logging. The typed values are
+// stored in Integer and String without boxing, so that creating and encoding
+// the strings, numbers, bools and times allocates nothing.
+type Field struct {
+	Key       string
+	Type      FieldType
+	Integer   int64
+	String    string
+	Interface interface{}
+}
+
+// String creates a field of string.
+func String(key, value string) Field {
+	return Field{Key: key, Type: StringType, String: value}
+}
+
+// Int creates a field of int.
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: IntType, Integer: int64(value)}
+}
+
+// Int64 creates a field of int64.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: IntType, Integer: value}
+}
+
+// Uint64 creates a field of uint64.
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Type: UintType, Integer: int64(value)}
+}
+
+// Float64 creates a field of float64.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: FloatType, Integer: int64(math.Float64bits(value))}
+}
+
+// Bool creates a field of bool.
+func Bool(key string, value bool) Field {
+	var i int64
+	if value {
+		i = 1
+	}
+
+	return Field{Key: key, Type: BoolType, Integer: i}
+}
+
+// Duration creates a field of time.Duration.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(value)}
+}
+
+// Time creates a field of time.Time. The time is stored as nanoseconds with
+// its location, the time out of the range of UnixNano is boxed.
+func Time(key string, value time.Time) Field {
+	if y := value.Year(); y < 1678 || y > 2261 {
+		return Field{Key: key, Type: TimeType, Interface: value}
+	}
+
+	return Field{Key: key, Type: TimeType, Integer: value.UnixNano(), Interface: value.Location()}
+}
+
+// Err creates a field of error keyed "error", the nil error is skipped.
+func Err(err error) Field {
+	return NamedErr("error", err)
+}
+
+// NamedErr creates a field of error, the nil error is skipped.
+func NamedErr(key string, err error) Field {
+	if err == nil {
+		return Field{Key: key, Type: SkipType}
+	}
+
+	return Field{Key: key, Type: ErrorType, Interface: err}
+}
+
+// Any creates a field of any value, the typed values are stored as the
+// fields created by their constructors, the others are formatted by fmt.
+func Any(key string, value interface{}) Field {
+	switch v := value.(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int64:
+		return Int64(key, v)
+	case int32:
+		return Int64(key, int64(v))
+	case uint:
+		return Uint64(key, uint64(v))
+	case uint64:
+		return Uint64(key, v)
+	case uint32:
+		return Uint64(key, uint64(v))
+	case float64:
+		return Float64(key, v)
+	case float32:
+		return Float64(key, float64(v))
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case time.Time:
+		return Time(key, v)
+	case error:
+		return NamedErr(key, v)
+	default:
+		return Field{Key: key, Type: AnyType, Interface: value}
+	}
+}
+
+// time returns the time of a TimeType field.
+func (f Field) time() time.Time {
+	if t, ok := f.Interface.(time.Time); ok {
+		return t
+	}
+	t := time.Unix(0, f.Integer)
+	if loc, ok := f.Interface.(*time.Location); ok {
+		t = t.In(loc)
+	}
+
+	return t
+}
+
+// appendFields appends fields to buf as " key=value" pairs, the values are
+// quoted if they contain spaces, '=', '"' or control characters.
+func appendFields(buf []byte, fields []Field) []byte {
+	for i := range fields {
+		f := &fields[i]
+		if f.Type == SkipType {
+			continue
+		}
+		buf = append(buf, ' ')
+		buf = appendString(buf, f.Key)
+		buf = append(buf, '=')
+		buf = appendValue(buf, f)
+	}
+
+	return buf
+}
+
+func appendValue(buf []byte, f *Field) []byte {
+	switch f.Type {
+	case StringType:
+		return appendString(buf, f.String)
+	case IntType:
+		return strconv.AppendInt(buf, f.Integer, 10)
+	case UintType:
+		return strconv.AppendUint(buf, uint64(f.Integer), 10)
+	case FloatType:
+		return strconv.AppendFloat(buf, math.Float64frombits(uint64(f.Integer)), 'g', -1, 64)
+	case BoolType:
+		return strconv.AppendBool(buf, f.Integer != 0)
+	case DurationType:
+		return append(buf, time.Duration(f.Integer).String()...)
+	case TimeType:
+		return f.time().AppendFormat(buf, time.RFC3339Nano)
+	case ErrorType:
+		return appendString(buf, f.Interface.(error).Error())
+	default:
+		return appendString(buf, fmt.Sprint(f.Interface))
+	}
+}
+
+// appendString appends s to buf, quoted if necessary.
+func appendString(buf []byte, s string) []byte {
+	if !needsQuote(s) {
+		return append(buf, s...)
+	}
+
+	return strconv.AppendQuote(buf, s)
+}
+
+func needsQuote(s string) bool {
+	if s == "" || !utf8.ValidString(s) {
+		return true
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+			return true
+		}
+	}
+
+	return false
+}
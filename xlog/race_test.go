@@ -0,0 +1,7 @@
+//go:build race
+
+package xlog
+
+func init() {
+	raceEnabled = true
+}
@@ -0,0 +1,437 @@
+package xlog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotation is the period of rotating files by time.
+type Rotation int
+
+const (
+	// RotateNone never rotates by time.
+	RotateNone Rotation = iota
+	RotateHourly
+	RotateDaily
+)
+
+// backupTimeFormat is the timestamp in the names of backups.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// FileConfig configures FileWriter.
+type FileConfig struct {
+	// Filename is the file to write, the directories are created if absent.
+	Filename string
+
+	// MaxSize is the size in bytes triggering rotation, 0 for unlimited.
+	MaxSize int64
+
+	// Rotation rotates the file at the start of every hour or day.
+	Rotation Rotation
+
+	// MaxBackups is the number of backups to retain, 0 for all.
+	MaxBackups int
+
+	// MaxAge is the age of backups to retain, 0 for any age.
+	MaxAge time.Duration
+
+	// Compress compresses the backups by gzip.
+	Compress bool
+
+	// ReopenOnSIGHUP reopens the file when the process receives SIGHUP,
+	// e.g. after logrotate moves it. It's ignored on Windows.
+	ReopenOnSIGHUP bool
+
+	// LocalTime names the backups and rotates by time in local time
+	// rather than UTC.
+	LocalTime bool
+}
+
+// FileWriter is the io.Writer writing to a file rotated by size and time,
+// the backups are named like app-2009-01-23T01-23-23.000.log beside the file.
+// It's safe for concurrent use, e.g.
+//
+//	w, err := xlog.NewFileWriter(xlog.FileConfig{Filename: "/var/log/app.log", MaxSize: 100 << 20})
+//	logger := xlog.NewStdLog(w, "", xlog.InfoLevel, xlog.LstdFlags)
+type FileWriter struct {
+	cfg FileConfig
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	// next is the time of the next rotation by time.
+	next time.Time
+	// err is the error of the failed reopening, the file is nil until
+	// Reopen succeeds.
+	err    error
+	closed bool
+
+	// mill triggers compressing and removing the backups.
+	mill chan struct{}
+	wg   sync.WaitGroup
+	// stop stops watching SIGHUP.
+	stop func()
+
+	now func() time.Time
+}
+
+// NewFileWriter opens cfg.Filename for appending.
+func NewFileWriter(cfg FileConfig) (*FileWriter, error) {
+	return newFileWriter(cfg, time.Now)
+}
+
+func newFileWriter(cfg FileConfig, now func() time.Time) (*FileWriter, error) {
+	if cfg.Filename == "" {
+		return nil, fmt.Errorf("Filename is required.")
+	}
+
+	w := &FileWriter{cfg: cfg, mill: make(chan struct{}, 1), now: now}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	w.wg.Add(1)
+	go w.millRun()
+	w.stop = func() {}
+	if cfg.ReopenOnSIGHUP {
+		w.stop = notifyReopen(w)
+	}
+
+	return w, nil
+}
+
+// Write writes p to the file, rotates the file before if p exceeds MaxSize
+// or the period elapses.
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return 0, w.errClosed()
+	}
+
+	exceeded := w.cfg.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.cfg.MaxSize
+	elapsed := !w.next.IsZero() && !w.clock().Before(w.next)
+	if exceeded || elapsed {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+
+	return n, err
+}
+
+// Rotate rotates the file immediately.
+func (w *FileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return w.errClosed()
+	}
+
+	return w.rotate()
+}
+
+// Reopen closes and reopens the file without rotation, so that writing
+// continues in the new file after the file is moved by others. It also
+// recovers the writer after the file failed to reopen.
+func (w *FileWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	if oerr := w.open(); oerr != nil {
+		return oerr
+	}
+
+	return err
+}
+
+// Sync commits the file to stable storage.
+func (w *FileWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return w.errClosed()
+	}
+
+	return w.file.Sync()
+}
+
+// Close closes the file, and waits for the backups being compressed.
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return os.ErrClosed
+	}
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+	}
+	w.file, w.closed = nil, true
+	w.mu.Unlock()
+
+	w.stop()
+	close(w.mill)
+	w.wg.Wait()
+
+	return err
+}
+
+func (w *FileWriter) clock() time.Time {
+	return w.now().In(w.location())
+}
+
+// location returns the location of rotating and naming backups.
+func (w *FileWriter) location() *time.Location {
+	if w.cfg.LocalTime {
+		return time.Local
+	}
+
+	return time.UTC
+}
+
+// errClosed returns the error of writing while the file is not open.
+func (w *FileWriter) errClosed() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	return os.ErrClosed
+}
+
+// open opens the file for appending, and computes the next rotation from
+// the modification time of the file, so that the file written in a passed
+// period rotates at once. The error is kept for the later writes.
+func (w *FileWriter) open() error {
+	w.err = w.openFile()
+	return w.err
+}
+
+func (w *FileWriter) openFile() error {
+	if err := os.MkdirAll(filepath.Dir(w.cfg.Filename), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(w.cfg.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	start := w.clock()
+	if mtime := info.ModTime().In(w.location()); info.Size() > 0 && mtime.Before(start) {
+		start = mtime
+	}
+	w.file, w.size = f, info.Size()
+	w.next = nextRotation(start, w.cfg.Rotation)
+
+	return nil
+}
+
+// rotate moves the file to a backup and opens a new one. The file is
+// reopened even if it fails to move, and is nil if it fails to reopen.
+func (w *FileWriter) rotate() error {
+	err := w.file.Close()
+	w.file = nil
+	if err == nil {
+		if err = os.Rename(w.cfg.Filename, w.backupName(w.clock())); os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	if oerr := w.open(); oerr != nil {
+		return oerr
+	}
+	if err != nil {
+		return err
+	}
+
+	select {
+	case w.mill <- struct{}{}:
+	default:
+		// Milling is already pending.
+	}
+
+	return nil
+}
+
+// nextRotation returns the start of the next period after t.
+func nextRotation(t time.Time, r Rotation) time.Time {
+	y, m, d := t.Date()
+	switch r {
+	case RotateHourly:
+		return time.Date(y, m, d, t.Hour()+1, 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// backupName returns the name of the backup rotated at t.
+func (w *FileWriter) backupName(t time.Time) string {
+	prefix, ext := w.split()
+	name := prefix + t.Format(backupTimeFormat) + ext
+	// Rotations in the same millisecond.
+	for i := 1; exists(name) || exists(name+".gz"); i++ {
+		name = fmt.Sprintf("%s%s.%d%s", prefix, t.Format(backupTimeFormat), i, ext)
+	}
+
+	return name
+}
+
+// split splits the file name into the prefix of backups with the directory,
+// e.g. "/var/log/app-", and the extension.
+func (w *FileWriter) split() (prefix, ext string) {
+	ext = filepath.Ext(w.cfg.Filename)
+	return strings.TrimSuffix(w.cfg.Filename, ext) + "-", ext
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// backup is a rotated file.
+type backup struct {
+	name string
+	t    time.Time
+}
+
+// backups returns the backups, the newest first.
+func (w *FileWriter) backups() ([]backup, error) {
+	prefix, ext := w.split()
+	entries, err := os.ReadDir(filepath.Dir(w.cfg.Filename))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix = filepath.Base(prefix)
+	var result []backup
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), prefix) {
+			continue
+		}
+		name := filepath.Join(filepath.Dir(w.cfg.Filename), e.Name())
+		stamp := strings.TrimSuffix(strings.TrimPrefix(e.Name(), prefix), ".gz")
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		stamp = strings.TrimSuffix(stamp, ext)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, stamp[:len(backupTimeFormat)], w.location())
+		if err != nil {
+			continue
+		}
+		result = append(result, backup{name: name, t: t})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].t.Equal(result[j].t) {
+			return result[i].name > result[j].name
+		}
+		return result[i].t.After(result[j].t)
+	})
+
+	return result, nil
+}
+
+func (w *FileWriter) millRun() {
+	defer w.wg.Done()
+	for range w.mill {
+		if err := w.millOnce(); err != nil {
+			fmt.Fprintf(os.Stderr, "xlog: failed to clean up backups of [%s]: %v\n", w.cfg.Filename, err)
+		}
+	}
+}
+
+// millOnce removes the backups exceeding MaxBackups or MaxAge, and
+// compresses the rest.
+func (w *FileWriter) millOnce() error {
+	if w.cfg.MaxBackups == 0 && w.cfg.MaxAge == 0 && !w.cfg.Compress {
+		return nil
+	}
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+
+	var (
+		cutoff time.Time
+		errs   []string
+	)
+	if w.cfg.MaxAge > 0 {
+		cutoff = w.clock().Add(-w.cfg.MaxAge)
+	}
+	for i, b := range backups {
+		stale := !cutoff.IsZero() && b.t.Before(cutoff)
+		if (w.cfg.MaxBackups > 0 && i >= w.cfg.MaxBackups) || stale {
+			if err := os.Remove(b.name); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err.Error())
+			}
+			continue
+		}
+		if w.cfg.Compress && !strings.HasSuffix(b.name, ".gz") {
+			if err := compress(b.name); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// compress compresses name into name.gz, and removes name.
+func compress(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+
+	return os.Remove(name)
+}
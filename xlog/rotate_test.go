@@ -0,0 +1,229 @@
+package xlog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is the clock of FileWriter in tests.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func readFile(t *testing.T, name string) string {
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gz
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestFileWriterSize(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+	w, err := newFileWriter(FileConfig{Filename: filepath.Join(dir, "logs", "app.log"), MaxSize: 10, MaxBackups: 2, Compress: true}, clock.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n", "eeee\n", "ffff\n", "gggg\n"} {
+		if _, err = w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		clock.add(time.Second)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte("closed")); err != os.ErrClosed {
+		t.Fatalf("Expect ErrClosed, got %v.", err)
+	}
+
+	want := []string{"app-2020-01-02T03-04-09.000.log.gz", "app-2020-01-02T03-04-11.000.log.gz", "app.log"}
+	got := listDir(t, filepath.Join(dir, "logs"))
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expect %v, got %v.", want, got)
+	}
+	for i, content := range []string{"cccc\ndddd\n", "eeee\nffff\n", "gggg\n"} {
+		if s := readFile(t, filepath.Join(dir, "logs", want[i])); s != content {
+			t.Fatalf("Expect [%s] in %s, got [%s].", content, want[i], s)
+		}
+	}
+}
+
+func TestFileWriterTime(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2020, 1, 2, 23, 59, 0, 0, time.UTC)}
+	name := filepath.Join(dir, "app.log")
+	// An old backup removed by MaxAge.
+	if err := os.WriteFile(filepath.Join(dir, "app-2019-12-01T00-00-00.000.log"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := newFileWriter(FileConfig{Filename: name, Rotation: RotateDaily, MaxAge: 7 * 24 * time.Hour}, clock.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Write([]byte("day 2\n"))
+	clock.add(time.Minute)
+	w.Write([]byte("day 3\n"))
+	clock.add(time.Hour)
+	w.Write([]byte("day 3 again\n"))
+	w.Close()
+
+	want := []string{"app-2020-01-03T00-00-00.000.log", "app.log"}
+	if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expect %v, got %v.", want, got)
+	}
+	if s := readFile(t, name); s != "day 3\nday 3 again\n" {
+		t.Fatalf("Unexpected content [%s].", s)
+	}
+}
+
+func TestFileWriterReopen(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	w, err := NewFileWriter(FileConfig{Filename: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("before\n"))
+	if err = os.Rename(name, name+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("after\n"))
+
+	if s := readFile(t, name+".1"); s != "before\n" {
+		t.Fatalf("Unexpected moved content [%s].", s)
+	}
+	if s := readFile(t, name); s != "after\n" {
+		t.Fatalf("Unexpected content [%s].", s)
+	}
+}
+
+func TestFileWriterStale(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2020, 1, 3, 8, 0, 0, 0, time.UTC)}
+	name := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(name, []byte("day 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The file was written in the previous day.
+	mtime := time.Date(2020, 1, 2, 22, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(name, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := newFileWriter(FileConfig{Filename: name, Rotation: RotateDaily}, clock.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("day 3\n"))
+	w.Close()
+
+	want := []string{"app-2020-01-03T08-00-00.000.log", "app.log"}
+	if got := listDir(t, dir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Expect %v, got %v.", want, got)
+	}
+	if s := readFile(t, filepath.Join(dir, want[0])); s != "day 2\n" {
+		t.Fatalf("Unexpected backup content [%s].", s)
+	}
+	if s := readFile(t, name); s != "day 3\n" {
+		t.Fatalf("Unexpected content [%s].", s)
+	}
+}
+
+func TestFileWriterOpenFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	name := filepath.Join(dir, "app.log")
+	w, err := NewFileWriter(FileConfig{Filename: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// The directory is replaced by a file, so the log file can't reopen.
+	if err = os.Rename(dir, dir+".old"); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(dir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Rotate(); err == nil {
+		t.Fatal("Expect rotating to fail.")
+	}
+	if _, err = w.Write([]byte("lost\n")); err == nil || err == os.ErrClosed {
+		t.Fatalf("Expect the error of reopening, got %v.", err)
+	}
+	if err = w.Reopen(); err == nil {
+		t.Fatal("Expect reopening to fail.")
+	}
+
+	// Reopen recovers the writer.
+	if err = os.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte("after\n")); err != nil {
+		t.Fatal(err)
+	}
+	if s := readFile(t, name); s != "after\n" {
+		t.Fatalf("Unexpected content [%s].", s)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build !windows
+
+package xlog
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyReopen reopens w on SIGHUP until the returned stop is called.
+func notifyReopen(w *FileWriter) (stop func()) {
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-c:
+				if err := w.Reopen(); err != nil {
+					fmt.Fprintf(os.Stderr, "xlog: failed to reopen [%s]: %v\n", w.cfg.Filename, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
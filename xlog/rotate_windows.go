@@ -0,0 +1,6 @@
+package xlog
+
+// notifyReopen does nothing, since there is no SIGHUP on Windows.
+func notifyReopen(w *FileWriter) (stop func()) {
+	return func() {}
+}
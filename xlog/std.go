@@ -57,8 +57,11 @@ type Logger struct {
 	mu     sync.Mutex // ensures atomic writes; protects the following fields
 	prefix string     // prefix on each line to identify the logger (but see Lmsgprefix)
 	level  atomic.Value
-	flag   int32     // properties
-	out    io.Writer // destination for output
+	enc    atomic.Value // holds encoderHolder, the text encoder if unset
+	back   atomic.Value // holds backendHolder, replaces enc and out if set
+	async  atomic.Value // holds *asyncWriter, nil if synchronous
+	flag   int32        // properties
+	out    io.Writer    // destination for output
 }
 
 // New creates a new Logger. The out variable sets the
@@ -80,6 +83,32 @@ func (l *Logger) Level() Level {
 	return l.level.Load().(Level)
 }
 
+// SetEncoder sets the Encoder of entries, e.g. NewJSONEncoder.
+func (l *Logger) SetEncoder(enc Encoder) {
+	l.enc.Store(encoderHolder{enc})
+}
+
+// Encoder returns the Encoder of entries.
+func (l *Logger) Encoder() Encoder {
+	if h, ok := l.enc.Load().(encoderHolder); ok && h.Encoder != nil {
+		return h.Encoder
+	}
+
+	return defaultEncoder
+}
+
+// SetBackend sets the Backend receiving the entries instead of the Encoder
+// and the writer of l, e.g. NewZapBackend. The nil Backend restores them.
+func (l *Logger) SetBackend(b Backend) {
+	l.back.Store(backendHolder{b})
+}
+
+// Backend returns the Backend of l, nil if unset.
+func (l *Logger) Backend() Backend {
+	h, _ := l.back.Load().(backendHolder)
+	return h.Backend
+}
+
 // Cheap integer to fixed-width decimal ASCII. Give a negative width to avoid zero-padding.
 func itoa(buf *[]byte, i int, wid int) {
 	// Assemble decimal in reverse order.
@@ -101,73 +130,6 @@ func (l *Logger) checkLevel(lvl Level) bool {
 	return l.Level().Enabled(lvl)
 }
 
-// formatHeader writes log header to buf in following order:
-//   * l.prefix (if it's not blank and Lmsgprefix is unset),
-//   * date and/or time (if corresponding flags are provided),
-//   * file and line number (if corresponding flags are provided),
-//   * function name (if corresponding flags are provided),
-//   * l.prefix (if it's not blank and Lmsgprefix is set).
-func (l *Logger) formatHeader(buf *[]byte, t time.Time, lvl Level, file string, line int, function string) {
-	flag := l.Flags()
-	if flag&Lmsgprefix == 0 {
-		l.mu.Lock()
-		*buf = append(*buf, l.prefix...)
-		l.mu.Unlock()
-	}
-	if flag&(Ldate|Ltime|Lmicroseconds) != 0 {
-		if flag&LUTC != 0 {
-			t = t.UTC()
-		}
-		if flag&Ldate != 0 {
-			year, month, day := t.Date()
-			itoa(buf, year, 4)
-			*buf = append(*buf, '/')
-			itoa(buf, int(month), 2)
-			*buf = append(*buf, '/')
-			itoa(buf, day, 2)
-			*buf = append(*buf, ' ')
-		}
-		if flag&(Ltime|Lmicroseconds) != 0 {
-			hour, min, sec := t.Clock()
-			itoa(buf, hour, 2)
-			*buf = append(*buf, ':')
-			itoa(buf, min, 2)
-			*buf = append(*buf, ':')
-			itoa(buf, sec, 2)
-			if flag&Lmicroseconds != 0 {
-				*buf = append(*buf, '.')
-				itoa(buf, t.Nanosecond()/1e3, 6)
-			}
-			*buf = append(*buf, ' ')
-		}
-	}
-	*buf = append(*buf, lvl.String()...)
-	*buf = append(*buf, " "...)
-	if flag&(Lshortfile|Llongfile) != 0 {
-		if flag&Lshortfile != 0 {
-			short := file
-			for i := len(file) - 1; i > 0; i-- {
-				if file[i] == '/' {
-					short = file[i+1:]
-					break
-				}
-			}
-			file = short
-		}
-		*buf = append(*buf, file...)
-		*buf = append(*buf, ':')
-		itoa(buf, line, -1)
-		*buf = append(*buf, " "...)
-	}
-	if flag&Lfunction != 0 {
-		*buf = append(*buf, function...)
-		*buf = append(*buf, "() "...)
-	}
-	if flag&Lmsgprefix != 0 {
-		*buf = append(*buf, l.prefix...)
-	}
-}
-
 // Output writes the output for a logging event. The string s contains
 // the text to print after the prefix specified by the flags of the
 // Logger. A newline is appended if the last character of s is not
@@ -175,6 +137,11 @@ func (l *Logger) formatHeader(buf *[]byte, t time.Time, lvl Level, file string,
 // provided for generality, although at the moment on all pre-defined
 // paths it will be 2.
 func (l *Logger) Output(calldepth int, lvl Level, s string) error {
+	return l.output(calldepth+1, lvl, s, nil, nil) // +1 for this frame.
+}
+
+// output writes s followed by the fields in ctx and fields, see Output.
+func (l *Logger) output(calldepth int, lvl Level, s string, ctx, fields []Field) error {
 	if !l.checkLevel(lvl) {
 		return nil
 	}
@@ -185,25 +152,96 @@ func (l *Logger) Output(calldepth int, lvl Level, s string) error {
 	line := 0
 	flag := atomic.LoadInt32(&l.flag)
 	if flag&(Lshortfile|Llongfile|Lfunction) != 0 {
-		pc := make([]uintptr, 1)
-		numFrames := runtime.Callers(calldepth, pc)
+		var pc [1]uintptr
+		numFrames := runtime.Callers(calldepth, pc[:])
 		if numFrames == 1 {
-			frames := runtime.CallersFrames(pc)
-			frame, _ := frames.Next()
+			frame := callerFrame(pc[0])
 			file = frame.File
 			line = frame.Line
 			function = frame.Function
 		}
 	}
-	buf := bufPool.Get().([]byte)
-	buf = buf[:0]
-	l.formatHeader(&buf, now, lvl, file, line, function)
-	buf = append(buf, s...)
-	if len(s) == 0 || s[len(s)-1] != '\n' {
-		buf = append(buf, '\n')
+	e := entryPool.Get().(*Entry)
+	l.mu.Lock()
+	prefix := l.prefix
+	l.mu.Unlock()
+	// Copy fields into the buffer of e, so that the variadic fields of the
+	// caller never escape to the heap.
+	buf := append(e.buf[:0], fields...)
+	*e = Entry{
+		Time:     now,
+		Level:    lvl,
+		Prefix:   prefix,
+		File:     file,
+		Line:     line,
+		Function: function,
+		Message:  s,
+		Context:  ctx,
+		buf:      buf,
+		text:     e.text,
+	}
+	if len(buf) > 0 {
+		e.Fields = buf
+	}
+	err := l.write(e, flag)
+	for i := range buf {
+		buf[i] = Field{}
+	}
+	*e = Entry{buf: buf[:0], text: e.text}
+	entryPool.Put(e)
+
+	return err
+}
+
+// callerFrames caches the frames of the call sites by pc, since
+// runtime.CallersFrames allocates for every call.
+var callerFrames struct {
+	sync.RWMutex
+	m map[uintptr]runtime.Frame
+}
+
+// callerFrame returns the frame of pc returned by runtime.Callers.
+func callerFrame(pc uintptr) runtime.Frame {
+	callerFrames.RLock()
+	frame, ok := callerFrames.m[pc]
+	callerFrames.RUnlock()
+	if ok {
+		return frame
+	}
+
+	frame, _ = runtime.CallersFrames([]uintptr{pc}).Next()
+	callerFrames.Lock()
+	if callerFrames.m == nil {
+		callerFrames.m = make(map[uintptr]runtime.Frame)
+	}
+	callerFrames.m[pc] = frame
+	callerFrames.Unlock()
+
+	return frame
+}
+
+// write writes e to the Backend if set, otherwise encodes e to l.out.
+func (l *Logger) write(e *Entry, flag int32) error {
+	if b := l.Backend(); b != nil {
+		return b.Write(e)
+	}
+
+	buf := l.Encoder().Encode(e.text[:0], e, flag)
+	var err error
+	if a := l.asyncWriter(); a != nil {
+		err = a.write(e.Level, buf)
+		if e.Level >= PanicLevel {
+			// Flushes before panic or os.Exit.
+			if serr := a.sync(); err == nil {
+				err = serr
+			}
+		}
+	} else {
+		_, err = l.out.Write(buf)
+	}
+	if cap(buf) <= maxEntryText {
+		e.text = buf
 	}
-	_, err := l.out.Write(buf)
-	bufPool.Put(buf)
 
 	return err
 }
@@ -347,6 +385,16 @@ func SetLevel(lvl Level) {
 	std.SetLevel(lvl)
 }
 
+// SetEncoder sets the Encoder of the standard logger.
+func SetEncoder(enc Encoder) {
+	std.SetEncoder(enc)
+}
+
+// SetBackend sets the Backend of the standard logger.
+func SetBackend(b Backend) {
+	std.SetBackend(b)
+}
+
 // These functions write to the standard logger.
 
 // Debug calls Output to print to the standard logger.
@@ -0,0 +1,71 @@
+package xlog
+
+import "os"
+
+// FieldLogger writes structured entries through a Logger, every entry is
+// the message followed by the fields of the FieldLogger and the call, e.g.
+//
+//	logger.With(xlog.String("module", "config")).Info("loaded", xlog.Int("keys", 3), xlog.Err(err))
+//
+// prints
+//
+//	2009/01/23 01:23:23.123123 INFO main.go:23 main.main() loaded module=config keys=3
+type FieldLogger struct {
+	l      *Logger
+	fields []Field
+}
+
+// With creates a FieldLogger writing to l with fields.
+func (l *Logger) With(fields ...Field) *FieldLogger {
+	return &FieldLogger{l: l, fields: append([]Field(nil), fields...)}
+}
+
+// With creates a FieldLogger writing to the standard logger with fields.
+func With(fields ...Field) *FieldLogger {
+	return std.With(fields...)
+}
+
+// With creates a child FieldLogger with fields appended to the ones of fl.
+func (fl *FieldLogger) With(fields ...Field) *FieldLogger {
+	merged := make([]Field, 0, len(fl.fields)+len(fields))
+	merged = append(merged, fl.fields...)
+
+	return &FieldLogger{l: fl.l, fields: append(merged, fields...)}
+}
+
+// Logger returns the Logger fl writes to.
+func (fl *FieldLogger) Logger() *Logger {
+	return fl.l
+}
+
+// Debug writes msg and fields at DebugLevel.
+func (fl *FieldLogger) Debug(msg string, fields ...Field) {
+	fl.l.output(3, DebugLevel, msg, fl.fields, fields)
+}
+
+// Info writes msg and fields at InfoLevel.
+func (fl *FieldLogger) Info(msg string, fields ...Field) {
+	fl.l.output(3, InfoLevel, msg, fl.fields, fields)
+}
+
+// Warn writes msg and fields at WarnLevel.
+func (fl *FieldLogger) Warn(msg string, fields ...Field) {
+	fl.l.output(3, WarnLevel, msg, fl.fields, fields)
+}
+
+// Error writes msg and fields at ErrorLevel.
+func (fl *FieldLogger) Error(msg string, fields ...Field) {
+	fl.l.output(3, ErrorLevel, msg, fl.fields, fields)
+}
+
+// Panic writes msg and fields at PanicLevel, then panics with msg.
+func (fl *FieldLogger) Panic(msg string, fields ...Field) {
+	fl.l.output(3, PanicLevel, msg, fl.fields, fields)
+	panic(msg)
+}
+
+// Fatal writes msg and fields at FatalLevel, then calls os.Exit(1).
+func (fl *FieldLogger) Fatal(msg string, fields ...Field) {
+	fl.l.output(3, FatalLevel, msg, fl.fields, fields)
+	os.Exit(1)
+}
@@ -0,0 +1,75 @@
+package xlog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewStdLog(&buf, "", InfoLevel, 0)
+	fl := l.With(String("module", "config"), Int("id", 7))
+
+	fl.Debug("ignored")
+	if buf.Len() != 0 {
+		t.Fatalf("Unexpected output [%s].", buf.String())
+	}
+
+	fl.With(Bool("ok", false)).Info("loaded keys",
+		String("path", "/etc/app config.yaml"),
+		Float64("ratio", 0.5),
+		Duration("took", 1500*time.Millisecond),
+		Time("at", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Err(errors.New("not found")),
+		Err(nil),
+		Any("tags", []string{"a", "b"}),
+	)
+	want := `INFO loaded keys module=config id=7 ok=false path="/etc/app config.yaml" ratio=0.5 took=1.5s at=2020-01-02T03:04:05Z error="not found" tags="[a b]"` + "\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("Expect [%s], got [%s].", want, got)
+	}
+}
+
+func TestFieldLoggerCaller(t *testing.T) {
+	var buf bytes.Buffer
+	NewStdLog(&buf, "", DebugLevel, Lshortfile).With().Warn("msg\n", Int("n", 1))
+	if got := buf.String(); !strings.HasPrefix(got, "WARN structured_test.go:") || !strings.HasSuffix(got, " msg n=1\n") {
+		t.Fatalf("Unexpected output [%s].", got)
+	}
+}
+
+func TestFieldEncodingAllocs(t *testing.T) {
+	fields := []Field{String("k", "v"), Int("i", 1), Float64("f", 1.5), Bool("b", true), Time("t", time.Now())}
+	buf := make([]byte, 0, 512)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = appendFields(buf[:0], fields)
+	})
+	if allocs != 0 {
+		t.Fatalf("Expect no allocation, got %v.", allocs)
+	}
+}
+
+// raceEnabled is set while testing with the race detector, which makes
+// sync.Pool drop the entries randomly.
+var raceEnabled bool
+
+func TestFieldLoggerAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops the entries with the race detector.")
+	}
+
+	for _, flag := range []int32{Ldate | Ltime, LstdFlags} {
+		fl := NewStdLog(io.Discard, "", InfoLevel, flag).With(String("module", "config"))
+		err := errors.New("failed")
+		allocs := testing.AllocsPerRun(100, func() {
+			fl.Info("loaded", String("k", "v"), Int("i", 1), Err(err))
+		})
+		if allocs != 0 {
+			t.Fatalf("Expect no allocation with flag [%d], got %v.", flag, allocs)
+		}
+	}
+}
@@ -1 +1,371 @@
 package xlog
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ZapLevel converts lvl into the level of zap.
+func ZapLevel(lvl Level) zapcore.Level {
+	switch lvl {
+	case DebugLevel:
+		return zapcore.DebugLevel
+	case InfoLevel:
+		return zapcore.InfoLevel
+	case WarnLevel:
+		return zapcore.WarnLevel
+	case ErrorLevel:
+		return zapcore.ErrorLevel
+	case PanicLevel:
+		return zapcore.PanicLevel
+	default:
+		return zapcore.FatalLevel
+	}
+}
+
+// LevelOf converts the level of zap into Level, zapcore.DPanicLevel is
+// PanicLevel.
+func LevelOf(lvl zapcore.Level) Level {
+	switch lvl {
+	case zapcore.DebugLevel:
+		return DebugLevel
+	case zapcore.InfoLevel:
+		return InfoLevel
+	case zapcore.WarnLevel:
+		return WarnLevel
+	case zapcore.ErrorLevel:
+		return ErrorLevel
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return PanicLevel
+	case zapcore.FatalLevel:
+		return FatalLevel
+	default:
+		if lvl < zapcore.DebugLevel {
+			return DebugLevel
+		}
+		return FatalLevel
+	}
+}
+
+// ZapLog serves Log by a zap.Logger. The level set by SetLevel filters the
+// entries before the core of the zap.Logger does.
+type ZapLog struct {
+	s     *zap.SugaredLogger
+	level zap.AtomicLevel
+}
+
+var _ Log = (*ZapLog)(nil)
+
+// NewZapLog creates the Log writing to z, the level is DebugLevel so that
+// z decides.
+func NewZapLog(z *zap.Logger) *ZapLog {
+	return &ZapLog{
+		s:     z.WithOptions(zap.AddCallerSkip(1)).Sugar(),
+		level: zap.NewAtomicLevelAt(zapcore.DebugLevel),
+	}
+}
+
+// SetLevel sets the level of l.
+func (l *ZapLog) SetLevel(lvl Level) {
+	l.level.SetLevel(ZapLevel(lvl))
+}
+
+// Level returns the level of l.
+func (l *ZapLog) Level() Level {
+	return LevelOf(l.level.Level())
+}
+
+// Sync flushes the zap.Logger.
+func (l *ZapLog) Sync() error {
+	return l.s.Sync()
+}
+
+// sprintln formats v in the manner of fmt.Println without the newline,
+// which zap appends.
+func sprintln(v []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+}
+
+func (l *ZapLog) Debug(v ...interface{}) {
+	if l.level.Enabled(zapcore.DebugLevel) {
+		l.s.Debug(v...)
+	}
+}
+
+func (l *ZapLog) Debugf(format string, v ...interface{}) {
+	if l.level.Enabled(zapcore.DebugLevel) {
+		l.s.Debugf(format, v...)
+	}
+}
+
+func (l *ZapLog) Debugln(v ...interface{}) {
+	if l.level.Enabled(zapcore.DebugLevel) {
+		l.s.Debug(sprintln(v))
+	}
+}
+
+func (l *ZapLog) Info(v ...interface{}) {
+	if l.level.Enabled(zapcore.InfoLevel) {
+		l.s.Info(v...)
+	}
+}
+
+func (l *ZapLog) Infof(format string, v ...interface{}) {
+	if l.level.Enabled(zapcore.InfoLevel) {
+		l.s.Infof(format, v...)
+	}
+}
+
+func (l *ZapLog) Infoln(v ...interface{}) {
+	if l.level.Enabled(zapcore.InfoLevel) {
+		l.s.Info(sprintln(v))
+	}
+}
+
+func (l *ZapLog) Warn(v ...interface{}) {
+	if l.level.Enabled(zapcore.WarnLevel) {
+		l.s.Warn(v...)
+	}
+}
+
+func (l *ZapLog) Warnf(format string, v ...interface{}) {
+	if l.level.Enabled(zapcore.WarnLevel) {
+		l.s.Warnf(format, v...)
+	}
+}
+
+func (l *ZapLog) Warnln(v ...interface{}) {
+	if l.level.Enabled(zapcore.WarnLevel) {
+		l.s.Warn(sprintln(v))
+	}
+}
+
+func (l *ZapLog) Error(v ...interface{}) {
+	if l.level.Enabled(zapcore.ErrorLevel) {
+		l.s.Error(v...)
+	}
+}
+
+func (l *ZapLog) Errorf(format string, v ...interface{}) {
+	if l.level.Enabled(zapcore.ErrorLevel) {
+		l.s.Errorf(format, v...)
+	}
+}
+
+func (l *ZapLog) Errorln(v ...interface{}) {
+	if l.level.Enabled(zapcore.ErrorLevel) {
+		l.s.Error(sprintln(v))
+	}
+}
+
+// Fatal logs by zap, which calls os.Exit(1), regardless of the level.
+func (l *ZapLog) Fatal(v ...interface{}) {
+	l.s.Fatal(v...)
+}
+
+// Fatalf logs by zap, which calls os.Exit(1), regardless of the level.
+func (l *ZapLog) Fatalf(format string, v ...interface{}) {
+	l.s.Fatalf(format, v...)
+}
+
+// Fatalln logs by zap, which calls os.Exit(1), regardless of the level.
+func (l *ZapLog) Fatalln(v ...interface{}) {
+	l.s.Fatal(sprintln(v))
+}
+
+// With creates a child ZapLog with fields, sharing the level of l.
+func (l *ZapLog) With(fields ...Field) *ZapLog {
+	args := make([]interface{}, 0, len(fields))
+	for i := range fields {
+		args = append(args, zapField(&fields[i]))
+	}
+
+	return &ZapLog{s: l.s.With(args...), level: l.level}
+}
+
+// zapBackend writes the entries of Logger to a zap core.
+type zapBackend struct {
+	core zapcore.Core
+}
+
+// NewZapBackend creates the Backend writing entries to the core of z, so
+// that a Logger, e.g. Default(), shares the sinks of z:
+//
+//	xlog.SetBackend(xlog.NewZapBackend(z))
+//
+// The caller is recorded if the flags of the Logger require it, the prefix
+// is the name of the logger.
+// The entries written by the core of NewZapCore are dropped, so that the
+// Logger and z never write to each other forever when z is built on the
+// core of the same Logger.
+func NewZapBackend(z *zap.Logger) Backend {
+	return &zapBackend{core: z.Core()}
+}
+
+func (b *zapBackend) Write(e *Entry) error {
+	if e.fromZap {
+		return nil
+	}
+
+	ent := zapcore.Entry{
+		Level:      ZapLevel(e.Level),
+		Time:       e.Time,
+		LoggerName: strings.TrimSpace(e.Prefix),
+		Message:    e.message(),
+	}
+	if e.Line > 0 {
+		ent.Caller = zapcore.EntryCaller{Defined: true, File: e.File, Line: e.Line, Function: e.Function}
+	}
+
+	ce := b.core.Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+	fields := make([]zapcore.Field, 0, len(e.Context)+len(e.Fields))
+	for _, fs := range [2][]Field{e.Context, e.Fields} {
+		for i := range fs {
+			fields = append(fields, zapField(&fs[i]))
+		}
+	}
+	ce.Write(fields...)
+
+	return nil
+}
+
+// zapField converts f into the field of zap.
+func zapField(f *Field) zapcore.Field {
+	switch f.Type {
+	case SkipType:
+		return zap.Skip()
+	case StringType:
+		return zap.String(f.Key, f.String)
+	case IntType:
+		return zap.Int64(f.Key, f.Integer)
+	case UintType:
+		return zap.Uint64(f.Key, uint64(f.Integer))
+	case FloatType:
+		return zap.Float64(f.Key, math.Float64frombits(uint64(f.Integer)))
+	case BoolType:
+		return zap.Bool(f.Key, f.Integer != 0)
+	case DurationType:
+		return zap.Duration(f.Key, time.Duration(f.Integer))
+	case TimeType:
+		return zap.Time(f.Key, f.time())
+	case ErrorType:
+		return zap.NamedError(f.Key, f.Interface.(error))
+	default:
+		return zap.Any(f.Key, f.Interface)
+	}
+}
+
+// zapCore is the zap core writing through Logger.
+type zapCore struct {
+	l      *Logger
+	fields []Field
+}
+
+// NewZapCore creates the zap core writing through l, the level of l decides
+// which entries are enabled, e.g.
+//
+//	z := zap.New(xlog.NewZapCore(xlog.Default()), zap.AddCaller())
+//
+// The name of the zap logger is ignored, l writes its own prefix. The
+// Backend of NewZapBackend drops the entries of the core, see NewZapBackend.
+func NewZapCore(l *Logger) zapcore.Core {
+	return &zapCore{l: l}
+}
+
+func (c *zapCore) Enabled(lvl zapcore.Level) bool {
+	return c.l.checkLevel(LevelOf(lvl))
+}
+
+func (c *zapCore) With(fields []zapcore.Field) zapcore.Core {
+	merged := make([]Field, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	for _, f := range fields {
+		merged = append(merged, fieldOf(f))
+	}
+
+	return &zapCore{l: c.l, fields: merged}
+}
+
+func (c *zapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *zapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	converted := make([]Field, len(fields))
+	for i, f := range fields {
+		converted[i] = fieldOf(f)
+	}
+
+	e := &Entry{
+		Time:     ent.Time,
+		Level:    LevelOf(ent.Level),
+		File:     "???",
+		Function: "???",
+		Message:  ent.Message,
+		Context:  c.fields,
+		Fields:   converted,
+		fromZap:  true,
+	}
+	if ent.Caller.Defined {
+		e.File, e.Line, e.Function = ent.Caller.File, ent.Caller.Line, ent.Caller.Function
+	}
+	c.l.mu.Lock()
+	e.Prefix = c.l.prefix
+	c.l.mu.Unlock()
+
+	return c.l.write(e, c.l.Flags())
+}
+
+// Sync flushes and syncs the Logger.
+func (c *zapCore) Sync() error {
+	return c.l.Sync()
+}
+
+// fieldOf converts the field of zap into Field, the complex values are
+// converted into maps and slices.
+func fieldOf(f zapcore.Field) Field {
+	switch f.Type {
+	case zapcore.SkipType:
+		return Field{Key: f.Key, Type: SkipType}
+	case zapcore.StringType:
+		return String(f.Key, f.String)
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		return Int64(f.Key, f.Integer)
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		return Uint64(f.Key, uint64(f.Integer))
+	case zapcore.Float64Type:
+		return Field{Key: f.Key, Type: FloatType, Integer: f.Integer}
+	case zapcore.Float32Type:
+		return Float64(f.Key, float64(math.Float32frombits(uint32(f.Integer))))
+	case zapcore.BoolType:
+		return Bool(f.Key, f.Integer == 1)
+	case zapcore.DurationType:
+		return Duration(f.Key, time.Duration(f.Integer))
+	case zapcore.TimeType:
+		t := time.Unix(0, f.Integer)
+		if loc, ok := f.Interface.(*time.Location); ok {
+			t = t.In(loc)
+		}
+		return Time(f.Key, t)
+	case zapcore.TimeFullType:
+		return Time(f.Key, f.Interface.(time.Time))
+	case zapcore.ErrorType:
+		return NamedErr(f.Key, f.Interface.(error))
+	default:
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		return Any(f.Key, enc.Fields[f.Key])
+	}
+}
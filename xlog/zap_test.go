@@ -0,0 +1,93 @@
+package xlog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestZapLog(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := NewZapLog(zap.New(core, zap.AddCaller()))
+	l.SetLevel(InfoLevel)
+
+	l.Debug("ignored")
+	l.Infoln("a", 1)
+	l.With(String("k", "v")).Errorf("failed %d", 2)
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("Expect 2 entries, got %v.", entries)
+	}
+	if e := entries[0]; e.Message != "a 1" || e.Level != zapcore.InfoLevel || !strings.HasSuffix(e.Caller.File, "zap_test.go") {
+		t.Fatalf("Unexpected entry %+v.", e)
+	}
+	if e := entries[1]; e.Message != "failed 2" || e.ContextMap()["k"] != "v" {
+		t.Fatalf("Unexpected entry %+v.", e)
+	}
+	if l.Level() != InfoLevel {
+		t.Fatalf("Expect InfoLevel, got %s.", l.Level())
+	}
+}
+
+func TestZapBackend(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := NewStdLog(&bytes.Buffer{}, "[app] ", DebugLevel, Lshortfile)
+	l.SetBackend(NewZapBackend(zap.New(core)))
+
+	l.Debug("filtered by zap")
+	l.With(Int("n", 1)).Warn("hello\n", Err(errors.New("boom")), Duration("took", time.Second))
+
+	entries := logs.AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("Expect 1 entry, got %v.", entries)
+	}
+	e := entries[0]
+	if e.Message != "hello" || e.Level != zapcore.WarnLevel || e.LoggerName != "[app]" || !strings.HasSuffix(e.Caller.File, "zap_test.go") {
+		t.Fatalf("Unexpected entry %+v.", e)
+	}
+	m := e.ContextMap()
+	if m["n"] != int64(1) || m["error"] != "boom" || m["took"] != time.Second {
+		t.Fatalf("Unexpected fields %v.", m)
+	}
+
+	l.SetBackend(nil)
+	if l.Backend() != nil {
+		t.Fatal("Expect no backend.")
+	}
+}
+
+func TestZapCore(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewStdLog(&buf, "", InfoLevel, 0)
+	l.SetEncoder(NewLogfmtEncoder(EncoderConfig{LevelKey: "level", MessageKey: "msg"}))
+	z := zap.New(NewZapCore(l)).With(zap.String("module", "config"))
+
+	z.Debug("ignored")
+	z.Info("loaded", zap.Int("keys", 3), zap.Float32("ratio", 0.5), zap.Strings("tags", []string{"a"}), zap.Error(nil))
+	want := `level=info msg=loaded module=config keys=3 ratio=0.5 tags=[a]` + "\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("Expect [%s], got [%s].", want, got)
+	}
+}
+
+func TestZapCycle(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := NewStdLog(&bytes.Buffer{}, "", InfoLevel, 0)
+	z := zap.New(zapcore.NewTee(core, NewZapCore(l)))
+	l.SetBackend(NewZapBackend(z))
+
+	l.Info("from logger")
+	z.Info("from zap")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 || entries[0].Message != "from logger" || entries[1].Message != "from zap" {
+		t.Fatalf("Unexpected entries %v.", entries)
+	}
+}